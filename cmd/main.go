@@ -2,45 +2,67 @@ package main
 
 import (
 	"context"
+	"flag"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/tarsuniversecentral/project-module/config"
 	"github.com/tarsuniversecentral/project-module/internal/api"
 	"github.com/tarsuniversecentral/project-module/internal/handlers"
 	"github.com/tarsuniversecentral/project-module/internal/models"
 	"github.com/tarsuniversecentral/project-module/internal/router"
 	"github.com/tarsuniversecentral/project-module/internal/services"
 	"github.com/tarsuniversecentral/project-module/pkg/database"
+	"github.com/tarsuniversecentral/project-module/pkg/database/migration"
+	"github.com/tarsuniversecentral/project-module/pkg/storage"
+	"github.com/tarsuniversecentral/project-module/pkg/utils"
 )
 
 // Server wraps an http.Server instance.
 type Server struct {
-	httpServer *http.Server
+	httpServer      *http.Server
+	shutdownTimeout time.Duration
+
+	// cancelRequests cancels the context of every request still running once the
+	// graceful shutdown gives up; handlers tracks those requests until they return.
+	cancelRequests context.CancelFunc
+	handlers       sync.WaitGroup
 }
 
-// NewServer creates a new Server instance with the provided router.
-func NewServer(router *mux.Router) *Server {
+// NewServer creates a new Server instance with the provided router. On shutdown, requests
+// get shutdownTimeout to finish before their contexts are cancelled.
+func NewServer(router *mux.Router, shutdownTimeout time.Duration) *Server {
 	port := os.Getenv("APP_PORT")
 	if port == "" {
 		port = "8080"
 	}
 
-	srv := &http.Server{
-		Addr:         ":" + port,
-		Handler:      router,
+	baseCtx, cancel := context.WithCancel(context.Background())
+	s := &Server{shutdownTimeout: shutdownTimeout, cancelRequests: cancel}
+	s.httpServer = &http.Server{
+		Addr: ":" + port,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			s.handlers.Add(1)
+			defer s.handlers.Done()
+			router.ServeHTTP(w, r)
+		}),
+		BaseContext:  func(net.Listener) context.Context { return baseCtx },
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
-	return &Server{httpServer: srv}
+	return s
 }
 
-// Start runs the server and handles graceful shutdown on SIGINT/SIGTERM.
+// Start runs the server and handles graceful shutdown on SIGINT/SIGTERM. It returns once
+// every request has finished, so the caller can then release what the handlers use.
 func (s *Server) Start() {
 	// Start the server in a goroutine.
 	go func() {
@@ -57,40 +79,122 @@ func (s *Server) Start() {
 	log.Println("Shutting down server...")
 
 	// Create a deadline for the shutdown.
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
 	defer cancel()
 
-	// Attempt graceful shutdown.
+	// Attempt graceful shutdown. Requests still running at the deadline, such as slow
+	// uploads or event streams, are cancelled; uploads then remove what they saved.
 	if err := s.httpServer.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+		log.Printf("Graceful shutdown timed out, cancelling in-flight requests: %v", err)
 	}
+	s.cancelRequests()
+	s.handlers.Wait()
 	log.Println("Server exiting")
 }
 
 func main() {
+	migrateDown := flag.Bool("migrate-down", false, "roll back the most recently applied migration and exit")
+	flag.Parse()
+
+	// Load the configuration.
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatal("Error loading config:", err)
+	}
+
+	log.Printf("Effective configuration: %s", cfg)
+
+	utils.SetCursorSigningKey([]byte(cfg.CursorSigningKey))
+	utils.SetPreviewTokenSigningKey([]byte(cfg.PreviewTokenSigningKey))
+
+	// Resolve table names, prefixed when several environments share the database.
+	tables, err := database.NewTableNamer(cfg.DBTablePrefix)
+	if err != nil {
+		log.Fatal("Error in DB_TABLE_PREFIX:", err)
+	}
+
+	dialect, err := database.NewDialect(cfg.DBDriver)
+	if err != nil {
+		log.Fatal("Error in DB_DRIVER:", err)
+	}
+
+	if *migrateDown {
+		rollbackLastMigration(cfg, tables, dialect)
+		return
+	}
+
 	// Initialize the database.
-	db, err := database.InitDatabase()
+	db, err := database.InitDatabase(cfg, tables, dialect)
 	if err != nil {
 		log.Fatal("Error initializing database:", err)
 	}
-	defer db.Close()
+
+	// Open the read replicas, if any are configured.
+	replicas, err := database.OpenReplicaSet(db, dialect.Driver(), cfg.DBReadReplicaDSNs, cfg.DBPool, cfg.DBReplicaHealthInterval)
+	if err != nil {
+		log.Fatal("Error initializing read replicas:", err)
+	}
 
 	// Initialize models.
-	projectModel := models.NewProjectModel(db)
+	projectModel := models.NewProjectModel(db, replicas, tables, dialect, cfg.DBQueryTimeout, cfg.DBSlowQueryThreshold)
 
 	// Initialize services.
-	projectService := services.NewProjectService(projectModel)
+	projectService := services.NewProjectService(projectModel, cfg)
+	fileStorage := storage.NewLocalStorage(cfg.UploadRoot)
+	fileService := services.NewFileService(cfg, fileStorage)
+
+	// Periodically delete stored files no project references.
+	orphanPurger := services.NewOrphanPurger(projectModel, fileStorage, cfg.OrphanPurgeGracePeriod)
+	if cfg.OrphanPurgeInterval > 0 {
+		orphanPurger.Start(cfg.OrphanPurgeInterval)
+	}
+
+	// Periodically correct drift in the projects' like counts.
+	countReconciler := services.NewCountReconciler(projectModel)
+	if cfg.CountReconcileInterval > 0 {
+		countReconciler.Start(cfg.CountReconcileInterval)
+	}
 
 	// Initialize handlers.
-	projectHandler := handlers.NewProjectHandler(projectService)
+	projectHandler := handlers.NewProjectHandler(projectService, fileService)
+	healthHandler := handlers.NewHealthHandler(db, fileService, cfg.StorageHealthCheck, cfg.RetryAfter.DBUnavailable)
+
+	metricsHandler := handlers.NewMetricsHandler(db)
+	docsHandler := handlers.NewDocsHandler()
 
 	// Create the composite API struct.
-	apiComposite := api.NewAPI(projectHandler)
+	apiComposite := api.NewAPI(projectHandler, healthHandler, metricsHandler, docsHandler)
+
+	// Spans are dropped until an exporter, e.g. OTLP, is installed here with
+	// tracing.SetTracer; see package tracing.
 
 	// Set up the router with all routes.
-	router := router.NewRouter(apiComposite)
+	router := router.NewRouter(apiComposite, cfg)
 
 	// Create and start the server.
-	server := NewServer(router)
+	server := NewServer(router, cfg.ShutdownTimeout)
 	server.Start()
+
+	// Close the database only now that no request or background job can still be using it.
+	orphanPurger.Close()
+	countReconciler.Close()
+	projectModel.Close()
+	replicas.Close()
+	if err := db.Close(); err != nil {
+		log.Printf("Error closing database: %v", err)
+	}
+	log.Println("Database connections closed")
+}
+
+// rollbackLastMigration reverts the most recently applied migration without starting the server.
+func rollbackLastMigration(cfg *config.Config, tables *database.TableNamer, dialect *database.Dialect) {
+	db, err := database.OpenDatabase(cfg, dialect)
+	if err != nil {
+		log.Fatal("Error connecting to database:", err)
+	}
+	defer db.Close()
+
+	if _, err := migration.RollbackLastMigration(db, cfg.MigrationsDir, database.Rewriter(tables, dialect)); err != nil {
+		log.Fatal("Error rolling back migration:", err)
+	}
 }
@@ -1,34 +1,438 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"net"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 // Config holds the database credentials and other configuration parameters.
+//
+// Fields holding credentials must be tagged `secret:"true"` so they are redacted
+// whenever the configuration is logged; see Redacted.
 type Config struct {
+	// DBDriver selects the database: "mysql" (the default) or "postgres".
+	DBDriver string
+	// DBSSLMode is the Postgres sslmode connection parameter. MySQL ignores it.
+	DBSSLMode string
+
 	DBUser     string
-	DBPassword string
+	DBPassword string `secret:"true"`
 	DBHost     string
 	DBPort     string
 	DBName     string
+
+	// DBTablePrefix is prepended to every table name, e.g. "acme_" turns projects into
+	// acme_projects, so several environments can share one database.
+	DBTablePrefix string
+
+	// MigrationsDir holds the NNNN_name_up.sql migrations and their _down.sql rollbacks.
+	// The default is relative to the repository root, the usual working directory, and
+	// depends on DBDriver since the two databases need different DDL.
+	MigrationsDir string
+
+	// DBConnectMaxAttempts is how many times startup tries to reach the database before
+	// giving up; DBConnectRetryDelay is the wait after the first failure, doubled after each.
+	DBConnectMaxAttempts int
+	DBConnectRetryDelay  time.Duration
+
+	// DBPool sizes the connection pools of the primary and the replicas.
+	DBPool PoolConfig
+	// DBQueryTimeout bounds each model call against the database. Zero disables the limit.
+	DBQueryTimeout time.Duration
+	// DBSlowQueryThreshold is how long a statement may run before it is logged as slow.
+	// Zero disables the slow-query log.
+	DBSlowQueryThreshold time.Duration
+
+	// DBReadReplicaDSNs lists MySQL DSNs of read replicas. Reads use the primary when empty.
+	DBReadReplicaDSNs []string `secret:"true"`
+	// DBReplicaHealthInterval is how often replicas are pinged to detect outages and recovery.
+	DBReplicaHealthInterval time.Duration
+
+	// CursorSigningKey signs pagination cursors. When empty a random per-process key is used.
+	CursorSigningKey string `secret:"true"`
+
+	// PreviewTokenSigningKey signs draft preview tokens. When empty a random per-process key is used.
+	PreviewTokenSigningKey string `secret:"true"`
+	// PreviewTokenTTL is how long a preview token stays valid.
+	PreviewTokenTTL time.Duration
+
+	// DebugBodyLogging enables logging of request and response bodies. Development aid only.
+	DebugBodyLogging bool
+	// DebugBodyLogMaxBytes caps how much of each body is captured for logging.
+	DebugBodyLogMaxBytes int
+	// DebugBodyLogRedactHeaders lists extra headers to redact; Authorization and cookies are always redacted.
+	DebugBodyLogRedactHeaders []string
+	// DebugBodyLogRedactFields lists JSON/form field names whose values are redacted.
+	DebugBodyLogRedactFields []string
+
+	// StorageHealthCheck adds an upload storage writability check to readiness.
+	StorageHealthCheck bool
+
+	// GithubLinkAllowedHosts restricts github_link to these hosts (and their subdomains).
+	// Defaults to github.com; set GITHUB_LINK_ALLOWED_HOSTS to an empty value to allow any valid URL.
+	GithubLinkAllowedHosts []string
+
+	// JWTSecret verifies HS256 bearer tokens on write routes. It is required unless
+	// AllowInsecureUserHeader is set.
+	JWTSecret string `secret:"true"`
+	// AllowInsecureUserHeader lets the service start without JWTSecret, trusting the interim
+	// X-User-ID header instead. Anyone can set that header, so it is for local development only.
+	AllowInsecureUserHeader bool
+	// JWTIssuer, when set, must match the token's iss claim.
+	JWTIssuer string
+
+	// AllowedOrigins lists the origins allowed to make cross-origin browser requests.
+	// "*" allows any origin (local development only). Empty disables CORS.
+	AllowedOrigins []string
+
+	// TrustProxyHeaders honors X-Forwarded-For when resolving client IPs. Only enable behind a trusted proxy.
+	TrustProxyHeaders bool
+	// TrustedProxies lists the IPs and CIDR ranges of the proxies in front of the service. The
+	// client is the right-most X-Forwarded-For address outside them, and the header is only
+	// honored on connections from them. With none listed, the right-most address is used,
+	// which suits a single proxy.
+	TrustedProxies []string
+
+	// RateLimitRPS is the sustained requests per second allowed per client IP. Zero disables rate limiting.
+	RateLimitRPS float64
+	// RateLimitBurst is how many requests a client may make at once before being throttled.
+	RateLimitBurst int
+	// RateLimitIdleTTL is how long an idle client's bucket is kept before it is swept.
+	RateLimitIdleTTL time.Duration
+
+	// MaxInFlightRequests caps concurrently handled requests; excess requests get 503. Zero disables it.
+	MaxInFlightRequests int
+
+	// UploadQuotaBytes caps the bytes a single client IP may upload per UploadQuotaWindow. Zero disables it.
+	UploadQuotaBytes  int64
+	UploadQuotaWindow time.Duration
+
+	// MaxUploadBytes caps the size of each uploaded file. Zero disables it.
+	MaxUploadBytes int64
+	// MaxUploadFiles caps the number of files in one upload request. Zero disables it.
+	MaxUploadFiles int
+	// MaxUploadRequestBytes caps the combined size of the files in one upload request. Zero disables it.
+	MaxUploadRequestBytes int64
+	// UploadConcurrency is how many files of one request are saved or deleted at once.
+	UploadConcurrency int
+	// UploadRoot is the directory holding the pdfs/ and images/ upload directories.
+	// Empty keeps them in the working directory.
+	UploadRoot string
+
+	// PDFPreviewCommand renders the first page of uploaded pitch decks to PNG previews.
+	// Empty disables previews. See services.PreviewGenerator for the placeholders.
+	PDFPreviewCommand string
+	PDFPreviewTimeout time.Duration
+
+	// OrphanPurgeInterval is how often stored files no project references are deleted.
+	// Zero disables the purge.
+	OrphanPurgeInterval time.Duration
+	// OrphanPurgeGracePeriod is how old an unreferenced file must be before it is purged, so
+	// uploads whose project is still being created are left alone.
+	OrphanPurgeGracePeriod time.Duration
+
+	// CountReconcileInterval is how often the projects' like counts are corrected against
+	// the likes recorded. Zero disables the job; POST /admin/counts/reconcile still works.
+	CountReconcileInterval time.Duration
+
+	// EventMaxSubscribers caps concurrent project event streams. Zero disables the events endpoint.
+	// Each stream holds a request open, so keep it well below MaxInFlightRequests when that is set.
+	EventMaxSubscribers int
+	// EventHeartbeatInterval is how often an idle event stream sends a keep-alive comment.
+	EventHeartbeatInterval time.Duration
+
+	// CDNBaseURL, when set, makes file requests redirect to the CDN instead of streaming the bytes.
+	CDNBaseURL string
+	// CDNVisibilities lists the project visibilities whose files are offloaded to the CDN.
+	// Files of other projects are still served directly.
+	CDNVisibilities []string
+
+	// GithubToken authenticates GitHub API calls for repository imports. Optional for public repos.
+	GithubToken      string `secret:"true"`
+	GithubAPIURL     string
+	GithubAPITimeout time.Duration
+
+	// ForceHTTPS redirects plaintext requests reported by the proxy to HTTPS and enables HSTS.
+	ForceHTTPS bool
+	// HSTSMaxAge is the max-age advertised in the Strict-Transport-Security header.
+	HSTSMaxAge time.Duration
+
+	// CompressionMinBytes is the smallest JSON response body gzipped for clients that accept
+	// it. Zero disables compression.
+	CompressionMinBytes int
+
+	// UnversionedRoutes also serves the API routes without the /api/v1 prefix, marked as
+	// deprecated, for clients that haven't moved to the versioned paths yet.
+	UnversionedRoutes bool
+
+	// ShutdownTimeout is how long in-flight requests get to finish on SIGINT/SIGTERM
+	// before they are cancelled.
+	ShutdownTimeout time.Duration
+
+	// RetryAfter holds the default backoff advertised for each throttling or unavailable condition.
+	RetryAfter RetryAfterConfig
+}
+
+// PoolConfig holds database connection pool limits.
+type PoolConfig struct {
+	MaxIdleConns    int
+	MaxOpenConns    int
+	ConnMaxLifetime time.Duration
+}
+
+// RetryAfterConfig holds the Retry-After delays sent with 429 and 503 responses.
+type RetryAfterConfig struct {
+	RateLimited    time.Duration
+	Overloaded     time.Duration
+	DBUnavailable  time.Duration
+	QuotaExhausted time.Duration
 }
 
-// LoadConfig loads the environment variables from the .env file and returns a Config instance.
+// defaultUploadConcurrency is used when UPLOAD_CONCURRENCY is unset or not positive.
+const defaultUploadConcurrency = 10
+
+// LoadConfig loads the environment variables from the .env file, if there is one, and
+// returns a validated Config instance. Variables already set in the environment win over
+// the file, so deployments can configure the service without a .env file at all.
 func LoadConfig() (*Config, error) {
 	// Load environment variables from the .env file.
 	if err := godotenv.Load(); err != nil {
-		return nil, err
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("failed to read .env: %w", err)
+		}
+		log.Println("No .env file found; using the process environment")
+	}
+
+	driver := getEnv("DB_DRIVER", "mysql")
+	migrationsDir := "./pkg/database/migration/migrations"
+	if driver == "postgres" {
+		migrationsDir = "./pkg/database/migration/migrations_postgres"
 	}
 
 	cfg := &Config{
+		DBDriver:  driver,
+		DBSSLMode: getEnv("DB_SSLMODE", "disable"),
+
 		DBUser:     os.Getenv("DB_USER"),
 		DBPassword: os.Getenv("DB_PASSWORD"),
 		DBHost:     os.Getenv("DB_HOST"),
 		DBPort:     os.Getenv("DB_PORT"),
 		DBName:     os.Getenv("DB_NAME"),
+
+		DBTablePrefix: os.Getenv("DB_TABLE_PREFIX"),
+
+		MigrationsDir: getEnv("MIGRATIONS_DIR", migrationsDir),
+
+		DBConnectMaxAttempts: getEnvInt("DB_CONNECT_MAX_ATTEMPTS", 5),
+		DBConnectRetryDelay:  getEnvDuration("DB_CONNECT_RETRY_DELAY", time.Second),
+
+		DBPool: PoolConfig{
+			MaxIdleConns:    getEnvInt("DB_MAX_IDLE_CONNS", 10),
+			MaxOpenConns:    getEnvInt("DB_MAX_OPEN_CONNS", 100),
+			ConnMaxLifetime: getEnvDuration("DB_CONN_MAX_LIFETIME", 5*time.Minute),
+		},
+		DBQueryTimeout:       getEnvDuration("DB_QUERY_TIMEOUT", 5*time.Second),
+		DBSlowQueryThreshold: getEnvDuration("DB_SLOW_QUERY_THRESHOLD", 500*time.Millisecond),
+
+		DBReadReplicaDSNs:       getEnvList("DB_READ_REPLICA_DSNS", nil),
+		DBReplicaHealthInterval: getEnvDuration("DB_REPLICA_HEALTH_INTERVAL", 10*time.Second),
+
+		CursorSigningKey: os.Getenv("CURSOR_SIGNING_KEY"),
+
+		PreviewTokenSigningKey: os.Getenv("PREVIEW_TOKEN_SIGNING_KEY"),
+		PreviewTokenTTL:        getEnvDuration("PREVIEW_TOKEN_TTL", 7*24*time.Hour),
+
+		DebugBodyLogging:          getEnvBool("DEBUG_BODY_LOGGING", false),
+		DebugBodyLogMaxBytes:      getEnvInt("DEBUG_BODY_LOG_MAX_BYTES", 4096),
+		DebugBodyLogRedactHeaders: getEnvList("DEBUG_BODY_LOG_REDACT_HEADERS", nil),
+		DebugBodyLogRedactFields:  getEnvList("DEBUG_BODY_LOG_REDACT_FIELDS", []string{"password", "token", "secret"}),
+
+		StorageHealthCheck: getEnvBool("STORAGE_HEALTH_CHECK", true),
+
+		GithubLinkAllowedHosts: getEnvList("GITHUB_LINK_ALLOWED_HOSTS", []string{"github.com"}),
+
+		JWTSecret: os.Getenv("JWT_SECRET"),
+		JWTIssuer: os.Getenv("JWT_ISSUER"),
+
+		AllowInsecureUserHeader: getEnvBool("ALLOW_INSECURE_USER_HEADER", false),
+
+		AllowedOrigins: getEnvList("ALLOWED_ORIGINS", nil),
+
+		TrustProxyHeaders: getEnvBool("TRUST_PROXY_HEADERS", false),
+		TrustedProxies:    getEnvList("TRUSTED_PROXIES", nil),
+
+		RateLimitRPS:     getEnvFloat("RATE_LIMIT_RPS", 10),
+		RateLimitBurst:   getEnvInt("RATE_LIMIT_BURST", 20),
+		RateLimitIdleTTL: getEnvDuration("RATE_LIMIT_IDLE_TTL", 10*time.Minute),
+
+		MaxInFlightRequests: getEnvInt("MAX_IN_FLIGHT_REQUESTS", 0),
+
+		UploadQuotaBytes:  getEnvInt64("UPLOAD_QUOTA_BYTES", 500<<20),
+		UploadQuotaWindow: getEnvDuration("UPLOAD_QUOTA_WINDOW", time.Hour),
+
+		MaxUploadBytes:        getEnvInt64("MAX_UPLOAD_BYTES", 25<<20),
+		MaxUploadFiles:        getEnvInt("MAX_UPLOAD_FILES", 20),
+		MaxUploadRequestBytes: getEnvInt64("MAX_UPLOAD_REQUEST_BYTES", 100<<20),
+		UploadConcurrency:     getEnvInt("UPLOAD_CONCURRENCY", defaultUploadConcurrency),
+		UploadRoot:            os.Getenv("UPLOAD_ROOT"),
+
+		PDFPreviewCommand: os.Getenv("PDF_PREVIEW_COMMAND"),
+		PDFPreviewTimeout: getEnvDuration("PDF_PREVIEW_TIMEOUT", 10*time.Second),
+
+		OrphanPurgeInterval:    getEnvDuration("ORPHAN_PURGE_INTERVAL", time.Hour),
+		OrphanPurgeGracePeriod: getEnvDuration("ORPHAN_PURGE_GRACE_PERIOD", 24*time.Hour),
+
+		CountReconcileInterval: getEnvDuration("COUNT_RECONCILE_INTERVAL", 6*time.Hour),
+
+		EventMaxSubscribers:    getEnvInt("EVENT_MAX_SUBSCRIBERS", 500),
+		EventHeartbeatInterval: getEnvDuration("EVENT_HEARTBEAT_INTERVAL", 30*time.Second),
+
+		CDNBaseURL:      strings.TrimRight(os.Getenv("CDN_BASE_URL"), "/"),
+		CDNVisibilities: getEnvList("CDN_VISIBILITIES", []string{"public"}),
+
+		GithubToken:      os.Getenv("GITHUB_TOKEN"),
+		GithubAPIURL:     getEnv("GITHUB_API_URL", "https://api.github.com"),
+		GithubAPITimeout: getEnvDuration("GITHUB_API_TIMEOUT", 10*time.Second),
+
+		ForceHTTPS: getEnvBool("FORCE_HTTPS", false),
+		HSTSMaxAge: getEnvDuration("HSTS_MAX_AGE", 180*24*time.Hour),
+
+		CompressionMinBytes: getEnvInt("COMPRESSION_MIN_BYTES", 1024),
+
+		UnversionedRoutes: getEnvBool("UNVERSIONED_ROUTES", true),
+
+		ShutdownTimeout: getEnvDuration("SHUTDOWN_TIMEOUT", 15*time.Second),
+
+		RetryAfter: RetryAfterConfig{
+			RateLimited:    getEnvDuration("RETRY_AFTER_RATE_LIMITED", time.Second),
+			Overloaded:     getEnvDuration("RETRY_AFTER_OVERLOADED", 5*time.Second),
+			DBUnavailable:  getEnvDuration("RETRY_AFTER_DB_UNAVAILABLE", 10*time.Second),
+			QuotaExhausted: getEnvDuration("RETRY_AFTER_QUOTA_EXHAUSTED", time.Minute),
+		},
+	}
+
+	if cfg.UploadConcurrency < 1 {
+		cfg.UploadConcurrency = defaultUploadConcurrency
 	}
 
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
 	return cfg, nil
 }
+
+// Validate reports every required setting that is missing or empty in one error, so a
+// misconfigured deployment fails at startup rather than with a driver error later. It also
+// rejects malformed trusted proxy entries.
+func (c *Config) Validate() error {
+	required := []struct {
+		name  string
+		value string
+	}{
+		{"DB_USER", c.DBUser},
+		{"DB_HOST", c.DBHost},
+		{"DB_PORT", c.DBPort},
+		{"DB_NAME", c.DBName},
+	}
+
+	var missing []string
+	for _, r := range required {
+		if strings.TrimSpace(r.value) == "" {
+			missing = append(missing, r.name)
+		}
+	}
+
+	// Without a secret any client could claim to be any user through X-User-ID.
+	if strings.TrimSpace(c.JWTSecret) == "" && !c.AllowInsecureUserHeader {
+		missing = append(missing, "JWT_SECRET (or ALLOW_INSECURE_USER_HEADER=true for local development)")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing required configuration: %s", strings.Join(missing, ", "))
+	}
+
+	for _, proxy := range c.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			return fmt.Errorf("invalid TRUSTED_PROXIES entry %q: want an IP address or CIDR range", proxy)
+		}
+	}
+	return nil
+}
+
+// getEnv returns the value of the variable, or def when it is unset or empty.
+func getEnv(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+// getEnvBool returns the boolean value of the variable, or def when it is unset or unparsable.
+func getEnvBool(key string, def bool) bool {
+	v, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+// getEnvInt returns the integer value of the variable, or def when it is unset or unparsable.
+func getEnvInt(key string, def int) int {
+	v, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+// getEnvInt64 returns the 64-bit integer value of the variable, or def when it is unset or unparsable.
+func getEnvInt64(key string, def int64) int64 {
+	v, err := strconv.ParseInt(os.Getenv(key), 10, 64)
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+// getEnvFloat returns the floating-point value of the variable, or def when it is unset or unparsable.
+func getEnvFloat(key string, def float64) float64 {
+	v, err := strconv.ParseFloat(os.Getenv(key), 64)
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+// getEnvDuration returns the duration value of the variable (e.g. "5s"), or def when it is unset or unparsable.
+func getEnvDuration(key string, def time.Duration) time.Duration {
+	v, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+// getEnvList splits a comma-separated variable into trimmed, non-empty values.
+// It returns def when the variable is unset.
+func getEnvList(key string, def []string) []string {
+	raw, ok := os.LookupEnv(key)
+	if !ok {
+		return def
+	}
+
+	var values []string
+	for _, part := range strings.Split(raw, ",") {
+		if trimmed := strings.TrimSpace(part); trimmed != "" {
+			values = append(values, trimmed)
+		}
+	}
+	return values
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const redactedValue = "[REDACTED]"
+
+// sensitiveNameParts catch credentials that were added without a secret tag.
+var sensitiveNameParts = []string{"password", "secret", "token", "key", "dsn"}
+
+// Redacted returns the effective configuration as a flat map keyed by field name, with
+// nested structs flattened to "Parent.Field". Secret fields are replaced by a placeholder
+// when set, so the output shows whether a credential is configured without revealing it.
+//
+// All logging of the configuration must go through Redacted (String uses it), so a new
+// secret only has to be tagged once. Fields whose names look like credentials are
+// redacted even without the tag.
+func (c *Config) Redacted() map[string]interface{} {
+	out := make(map[string]interface{})
+	flatten(reflect.ValueOf(*c), "", false, out)
+	return out
+}
+
+// String renders the redacted configuration as JSON, so printing a Config can't leak secrets.
+func (c *Config) String() string {
+	b, err := json.Marshal(c.Redacted())
+	if err != nil {
+		return "{}"
+	}
+	return string(b)
+}
+
+func flatten(v reflect.Value, prefix string, secret bool, out map[string]interface{}) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := prefix + field.Name
+		value := v.Field(i)
+		fieldSecret := secret || field.Tag.Get("secret") == "true" || looksSensitive(field.Name)
+
+		if value.Kind() == reflect.Struct && field.Type != reflect.TypeOf(time.Time{}) {
+			flatten(value, name+".", fieldSecret, out)
+			continue
+		}
+
+		switch {
+		case fieldSecret && value.IsZero():
+			out[name] = ""
+		case fieldSecret:
+			out[name] = redactedValue
+		case field.Type == reflect.TypeOf(time.Duration(0)):
+			out[name] = value.Interface().(time.Duration).String()
+		default:
+			out[name] = value.Interface()
+		}
+	}
+}
+
+func looksSensitive(name string) bool {
+	lower := strings.ToLower(name)
+	for _, part := range sensitiveNameParts {
+		if strings.Contains(lower, part) {
+			return true
+		}
+	}
+	return false
+}
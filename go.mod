@@ -8,8 +8,27 @@ require (
 	github.com/joho/godotenv v1.5.1
 )
 
+require github.com/golang-jwt/jwt/v5 v5.2.2
+
+require golang.org/x/time v0.9.0
+
+require github.com/lib/pq v1.10.9
+
+require github.com/prometheus/client_golang v1.20.5
+
+require (
+	github.com/beorn7/perks v1.0.1 // indirect
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/klauspost/compress v1.17.9 // indirect
+	github.com/munnerz/goautoneg v0.0.0-20191010083416-a7dc8b61c822 // indirect
+	github.com/prometheus/client_model v0.6.1 // indirect
+	github.com/prometheus/common v0.55.0 // indirect
+	github.com/prometheus/procfs v0.15.1 // indirect
+	golang.org/x/sys v0.22.0 // indirect
+	google.golang.org/protobuf v1.34.2 // indirect
+)
+
 require (
 	filippo.io/edwards25519 v1.1.0 // indirect
 	github.com/google/uuid v1.6.0
-	golang.org/x/exp v0.0.0-20250128182459-e0ece0dbea4c
 )
@@ -6,10 +6,16 @@ import (
 
 type API struct {
 	ProjectHandler *handler.ProjectHandler
+	HealthHandler  *handler.HealthHandler
+	MetricsHandler *handler.MetricsHandler
+	DocsHandler    *handler.DocsHandler
 }
 
-func NewAPI(projectHandler *handler.ProjectHandler) *API {
+func NewAPI(projectHandler *handler.ProjectHandler, healthHandler *handler.HealthHandler, metricsHandler *handler.MetricsHandler, docsHandler *handler.DocsHandler) *API {
 	return &API{
 		ProjectHandler: projectHandler,
+		HealthHandler:  healthHandler,
+		MetricsHandler: metricsHandler,
+		DocsHandler:    docsHandler,
 	}
 }
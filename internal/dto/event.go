@@ -0,0 +1,41 @@
+package dto
+
+// Project event types pushed to subscribers of a project's event stream.
+const (
+	// ProjectEventStatus carries the project's current counts and status after a change.
+	ProjectEventStatus = "status"
+	// ProjectEventRemoved is sent when the project is deleted or stops being viewable.
+	// It is the last event of the stream.
+	ProjectEventRemoved = "removed"
+)
+
+// ProjectStatus is the live state of a project sent with status events.
+type ProjectStatus struct {
+	LikeCount    int        `json:"like_count"`
+	CommentCount int        `json:"comment_count"`
+	ViewCount    int        `json:"view_count"`
+	Verified     bool       `json:"verified"`
+	Visibility   Visibility `json:"visibility"`
+}
+
+// ProjectEvent is a change to a project, published to its subscribers.
+type ProjectEvent struct {
+	Type      string         `json:"type"`
+	ProjectID int            `json:"project_id"`
+	Status    *ProjectStatus `json:"status,omitempty"`
+}
+
+// NewProjectStatusEvent returns a status event with the project's current counts and status.
+func NewProjectStatusEvent(p *Project) ProjectEvent {
+	return ProjectEvent{
+		Type:      ProjectEventStatus,
+		ProjectID: p.ID,
+		Status: &ProjectStatus{
+			LikeCount:    p.LikeCount,
+			CommentCount: p.CommentCount,
+			ViewCount:    p.ViewCount,
+			Verified:     p.Verified,
+			Visibility:   p.Visibility,
+		},
+	}
+}
@@ -3,11 +3,35 @@ package dto
 type SavedFiles struct {
 	ImageFiles []string
 	PDFFiles   []string
+	// PDFPreviews maps a pitch deck filename to its first-page preview image, stored with the images.
+	PDFPreviews map[string]string
+	// OriginalNames maps each uploaded file's stored name to the name it was uploaded under.
+	OriginalNames map[string]string
 }
 
+// FileReference describes the project a stored file belongs to.
+type FileReference struct {
+	ProjectID  int
+	Visibility Visibility
+	// OriginalName is the name the file was uploaded under, or empty when unknown,
+	// e.g. for previews and files uploaded before original names were kept.
+	OriginalName string
+}
+
+// File types, which are also the storage directories the files are kept in.
+const (
+	FileTypePDFs   = "pdfs"
+	FileTypeImages = "images"
+)
+
 type FileResult struct {
+	// FileType is FileTypePDFs or FileTypeImages.
 	FileType string
 	Filename string
+	// OriginalName is the name the file was uploaded under, if it was uploaded.
+	OriginalName string
+	// PreviewOf names the pitch deck this file is a preview of, if any.
+	PreviewOf string
 }
 
 // ConstructFileResults converts a SavedFiles instance into a slice of FileResult.
@@ -17,7 +41,7 @@ func ConstructFileResults(savedFiles SavedFiles) []FileResult {
 	// Process image files
 	for _, file := range savedFiles.ImageFiles {
 		fileResults = append(fileResults, FileResult{
-			FileType: "images",
+			FileType: FileTypeImages,
 			Filename: file,
 		})
 	}
@@ -25,10 +49,19 @@ func ConstructFileResults(savedFiles SavedFiles) []FileResult {
 	// Process PDF files
 	for _, file := range savedFiles.PDFFiles {
 		fileResults = append(fileResults, FileResult{
-			FileType: "pdfs",
+			FileType: FileTypePDFs,
 			Filename: file,
 		})
 	}
 
+	// Process pitch deck previews
+	for deck, preview := range savedFiles.PDFPreviews {
+		fileResults = append(fileResults, FileResult{
+			FileType:  FileTypeImages,
+			Filename:  preview,
+			PreviewOf: deck,
+		})
+	}
+
 	return fileResults
 }
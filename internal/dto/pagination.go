@@ -0,0 +1,51 @@
+package dto
+
+import "time"
+
+// Pagination defaults shared by all list endpoints.
+const (
+	DefaultPerPage = 20
+	MaxPerPage     = 100
+)
+
+// PaginatedResponse is the uniform envelope returned by list endpoints.
+type PaginatedResponse[T any] struct {
+	Data       []T `json:"items"`
+	Page       int `json:"page"`
+	PerPage    int `json:"page_size"`
+	Total      int `json:"total"`
+	TotalPages int `json:"total_pages"`
+
+	// LastModified is the newest updated_at across the listed collection, used as a weak
+	// Last-Modified validator. Zero when unknown; never serialized.
+	LastModified time.Time `json:"-"`
+}
+
+// NewPaginatedResponse builds the envelope and computes TotalPages from total and perPage.
+// A nil data slice is replaced with an empty one so it serializes as [] rather than null.
+func NewPaginatedResponse[T any](data []T, page, perPage, total int) PaginatedResponse[T] {
+	if data == nil {
+		data = []T{}
+	}
+
+	totalPages := 0
+	if perPage > 0 {
+		totalPages = (total + perPage - 1) / perPage
+	}
+
+	return PaginatedResponse[T]{
+		Data:       data,
+		Page:       page,
+		PerPage:    perPage,
+		Total:      total,
+		TotalPages: totalPages,
+	}
+}
+
+// Offset returns the number of rows to skip for the given 1-based page.
+func Offset(page, perPage int) int {
+	if page < 1 {
+		return 0
+	}
+	return (page - 1) * perPage
+}
@@ -0,0 +1,50 @@
+package dto
+
+import "encoding/json"
+
+// PatchField records whether a JSON key was present in a partial update and, if so,
+// whether it was null. It lets PATCH handlers tell "leave unchanged" (key absent)
+// apart from "clear" (key present with null).
+type PatchField[T any] struct {
+	Set   bool
+	Null  bool
+	Value T
+}
+
+// UnmarshalJSON is only invoked when the key is present, including for a JSON null.
+func (f *PatchField[T]) UnmarshalJSON(data []byte) error {
+	f.Set = true
+	if string(data) == "null" {
+		f.Null = true
+		var zero T
+		f.Value = zero
+		return nil
+	}
+	f.Null = false
+	return json.Unmarshal(data, &f.Value)
+}
+
+// ProjectPatch is the body of PATCH /projects/{id}. Each field follows the same rules:
+//
+//   - key absent: the stored value is left unchanged;
+//   - key present with null: the stored value is cleared (set to NULL);
+//   - key present with a value: the stored value is replaced.
+//
+// Title is required on every project, so it can be replaced but not cleared.
+// An empty looking_for array clears the list just like null does.
+// Visibility and Currency always have a value, so they can be replaced but not cleared.
+//
+// Industry is the primary entry of Industries. Setting industries also updates industry
+// to the first entry, and setting only industry replaces industries with that one value.
+type ProjectPatch struct {
+	Title        PatchField[string]     `json:"title"`
+	Subtitle     PatchField[string]     `json:"subtitle"`
+	Industry     PatchField[string]     `json:"industry"`
+	Industries   PatchField[[]string]   `json:"industries"`
+	Description  PatchField[string]     `json:"description"`
+	ProjectValue PatchField[float64]    `json:"project_value"`
+	Currency     PatchField[string]     `json:"currency"`
+	LookingFor   PatchField[[]string]   `json:"looking_for"`
+	GithubLink   PatchField[string]     `json:"github_link"`
+	Visibility   PatchField[Visibility] `json:"visibility"`
+}
@@ -1,6 +1,13 @@
 package dto
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
 
 type LookingFor string
 
@@ -12,22 +19,121 @@ const (
 	Buyers     LookingFor = "Buyers"
 )
 
+// Visibility controls who can see a project.
+type Visibility string
+
+// Valid values for Visibility.
+const (
+	// VisibilityPublic projects appear in lists and can be fetched by anyone.
+	VisibilityPublic Visibility = "public"
+	// VisibilityUnlisted projects are hidden from lists but can be fetched by ID.
+	VisibilityUnlisted Visibility = "unlisted"
+	// VisibilityPrivate projects are only visible to their owner.
+	VisibilityPrivate Visibility = "private"
+)
+
 type Project struct {
-	ID           int          `json:"id"`
-	Title        string       `json:"title"`
-	Subtitle     string       `json:"subtitle,omitempty"`
-	Industry     string       `json:"industry,omitempty"`
-	Description  string       `json:"description,omitempty"`
-	PitchDecks   []string     `json:"pitch_decks,omitempty"`
-	ProjectValue float64      `json:"project_value,omitempty"`
-	LookingFor   []string     `json:"looking_for,omitempty"`
-	Images       []string     `json:"images,omitempty"`
-	GithubLink   string       `json:"github_link,omitempty"`
-	TeamMembers  []TeamMember `json:"team_members,omitempty"`
-	LikeCount    int          `json:"like_count"`
-	CommentCount int          `json:"comment_count"`
-	ViewCount    int          `json:"view_count"`
-	Verified     bool         `json:"verified"`
+	ID          int      `json:"id"`
+	Title       string   `json:"title"`
+	Subtitle    string   `json:"subtitle,omitempty"`
+	Industry    string   `json:"industry,omitempty"` // Primary industry, the first of Industries. Kept for older clients.
+	Industries  []string `json:"industries,omitempty"`
+	Description string   `json:"description,omitempty"`
+	PitchDecks  []string `json:"pitch_decks,omitempty"`
+	// PitchDeckPreviews maps a pitch deck filename to its preview image filename.
+	PitchDeckPreviews map[string]string `json:"pitch_deck_previews,omitempty"`
+	ProjectValue      float64           `json:"project_value,omitempty"` // Major units of Currency, kept to two decimal places.
+	Currency          string            `json:"currency"`                // ISO 4217 code of ProjectValue.
+	LookingFor        []string          `json:"looking_for,omitempty"`
+	Images            []string          `json:"images,omitempty"`
+	CoverImage        string            `json:"cover_image,omitempty"` // Primary image, or the first one when none is marked.
+	GithubLink        string            `json:"github_link,omitempty"`
+	TeamMembers       []TeamMember      `json:"team_members,omitempty"`
+	Lead              *TeamMember       `json:"lead,omitempty"`
+	LikeCount         int               `json:"like_count"`
+	CommentCount      int               `json:"comment_count"`
+	ViewCount         int               `json:"view_count"`
+	Verified          bool              `json:"verified"`
+	Visibility        Visibility        `json:"visibility"`
+	OwnerID           int               `json:"owner_id,omitempty"` // User who created the project; 0 for projects from before owners were recorded.
+	CreatedAt         Time              `json:"created_at"`
+	UpdatedAt         Time              `json:"updated_at"`
+
+	// OriginalNames maps an uploaded file's stored name to the name it was uploaded under.
+	// It is only written when the files are stored; downloads report the original name.
+	OriginalNames map[string]string `json:"-"`
+}
+
+// APIBasePath prefixes the versioned API routes and the paths returned in responses.
+const APIBasePath = "/api/v1"
+
+// PreviewTokenResponse is returned when a preview token is issued. URL is the project's
+// path with the token attached, ready to share.
+type PreviewTokenResponse struct {
+	Token     string `json:"token"`
+	ExpiresAt Time   `json:"expires_at"`
+	URL       string `json:"url"`
+}
+
+// CountResponse reports how many projects match a filter.
+type CountResponse struct {
+	Total int `json:"total"`
+}
+
+// LikeResponse reports the caller's like state and the project's like count after a like or unlike.
+type LikeResponse struct {
+	Liked     bool `json:"liked"`
+	LikeCount int  `json:"like_count"`
+}
+
+// LikeAction is what a like batch item does to the caller's like of a project.
+type LikeAction string
+
+// Valid values for LikeAction.
+const (
+	LikeActionLike   LikeAction = "like"
+	LikeActionUnlike LikeAction = "unlike"
+)
+
+// LikeBatchItem is one entry of the body of POST /likes/batch.
+type LikeBatchItem struct {
+	ProjectID int        `json:"project_id"`
+	Action    LikeAction `json:"action"`
+}
+
+// Values for LikeBatchResult.Status.
+const (
+	// LikeStatusApplied means the like was added or removed.
+	LikeStatusApplied = "applied"
+	// LikeStatusUnchanged means the project was already in the requested state.
+	LikeStatusUnchanged = "unchanged"
+	// LikeStatusSuperseded means a later item in the batch was for the same project and
+	// was applied instead.
+	LikeStatusSuperseded = "superseded"
+	// LikeStatusNotFound means the project doesn't exist or the caller can't see it.
+	LikeStatusNotFound = "not_found"
+)
+
+// LikeBatchResult reports what happened to one item of a like batch, in request order.
+// Liked and LikeCount are only set for applied and unchanged items.
+type LikeBatchResult struct {
+	ProjectID int        `json:"project_id"`
+	Action    LikeAction `json:"action"`
+	Status    string     `json:"status"`
+	Liked     bool       `json:"liked"`
+	LikeCount int        `json:"like_count"`
+}
+
+// LikeBatchResponse is the body returned by POST /likes/batch.
+type LikeBatchResponse struct {
+	Results []LikeBatchResult `json:"results"`
+}
+
+// LikeOutcome is the result of applying one like or unlike: whether it changed anything
+// and the project's like count afterwards.
+type LikeOutcome struct {
+	Changed   bool
+	LikeCount int
 }
 
 type TeamMember struct {
@@ -36,8 +142,122 @@ type TeamMember struct {
 	ProfileURL string `json:"profile_url,omitempty"`
 	Title      string `json:"title,omitempty"`
 	Role       string `json:"role,omitempty"`
+	IsLead     bool   `json:"is_lead"`
+	Version    int    `json:"version"` // Incremented by every update; send it back to detect concurrent edits.
+}
+
+// ReassignTeamMembersRequest is the body of POST /admin/teammembers/reassign.
+type ReassignTeamMembersRequest struct {
+	MemberIDs       []int `json:"member_ids"`
+	TargetProjectID int   `json:"target_project_id"`
+}
+
+// IndustryValueStat aggregates project_value across the projects of one industry.
+type IndustryValueStat struct {
+	Industry string  `json:"industry"`
+	Total    float64 `json:"total"`
+	Average  float64 `json:"average"`
+	Count    int     `json:"count"`
+}
+
+// ProjectFilter narrows project list queries. Zero values don't filter.
+// A project matches Industries if it has any of them, and LookingFor if it has all of them.
+// Only public projects are listed unless OwnerID is set, which lists all of that user's projects.
+type ProjectFilter struct {
+	Industries []string
+	LookingFor []string
+	OwnerID    int
+}
+
+// Project field limits. The text limits match the widths of the projects columns, except
+// description, a TEXT column, which is capped well below its 64 KiB.
+const (
+	MaxTitleLength       = 255
+	MaxSubtitleLength    = 255
+	MaxDescriptionLength = 10000
+	MaxGithubLinkLength  = 255
+
+	// MaxProjectValue is the largest value a DECIMAL(15,2) column holds.
+	MaxProjectValue = 9999999999999.99
+)
+
+// DefaultCurrency is the currency of projects created without one.
+const DefaultCurrency = "USD"
+
+// supportedCurrencies are the ISO 4217 codes a project_value can be given in. The column
+// keeps two decimal places whatever the currency, so amounts are in major units (dollars,
+// not cents); currencies with three minor digits would lose precision and are left out.
+var supportedCurrencies = map[string]struct{}{
+	"USD": {},
+	"EUR": {},
+	"GBP": {},
+	"JPY": {},
+	"CHF": {},
+	"CAD": {},
+	"AUD": {},
+	"CNY": {},
+	"INR": {},
+	"SGD": {},
 }
 
+// Validate checks the project's own fields: a non-empty title, text within the length
+// limits, a non-negative project_value and, when present, an absolute http(s) github_link.
+// Industries, visibility and the allowed github_link hosts are checked by the service.
+func (p *Project) Validate() error {
+	if p.Title == "" {
+		return errors.New("title is required")
+	}
+	if err := ValidateTextLength("title", p.Title, MaxTitleLength); err != nil {
+		return err
+	}
+	if err := ValidateTextLength("subtitle", p.Subtitle, MaxSubtitleLength); err != nil {
+		return err
+	}
+	if err := ValidateTextLength("description", p.Description, MaxDescriptionLength); err != nil {
+		return err
+	}
+	if err := ValidateProjectValue(p.ProjectValue); err != nil {
+		return err
+	}
+	if p.GithubLink != "" {
+		if err := ValidateTextLength("github_link", p.GithubLink, MaxGithubLinkLength); err != nil {
+			return err
+		}
+		if err := ValidateGithubLink(p.GithubLink, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateTextLength checks that value has at most max characters.
+func ValidateTextLength(field, value string, max int) error {
+	if n := utf8.RuneCountInString(value); n > max {
+		return fmt.Errorf("%s is %d characters long, at most %d are allowed", field, n, max)
+	}
+	return nil
+}
+
+// ValidateProjectValue checks that v is a finite, non-negative amount the column can store.
+func ValidateProjectValue(v float64) error {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return errors.New("project_value must be a number")
+	}
+	if v < 0 {
+		return fmt.Errorf("project_value cannot be negative: %v", v)
+	}
+	if v > MaxProjectValue {
+		return fmt.Errorf("project_value %v is too large; at most %.2f is allowed", v, MaxProjectValue)
+	}
+	return nil
+}
+
+// MaxIndustries caps how many industries a project can list.
+const MaxIndustries = 10
+
+// maxIndustryLength matches the width of the industry columns.
+const maxIndustryLength = 255
+
 var validLookingForValues = map[LookingFor]struct{}{
 	Investment: {},
 	Employees:  {},
@@ -45,6 +265,22 @@ var validLookingForValues = map[LookingFor]struct{}{
 	Buyers:     {},
 }
 
+// NormalizeLookingFor trims each looking_for value and drops empty values and duplicates,
+// keeping the original order, so a value is never stored twice.
+func NormalizeLookingFor(values []string) []string {
+	seen := make(map[string]struct{}, len(values))
+	lookingFor := []string{}
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if _, dup := seen[v]; v == "" || dup {
+			continue
+		}
+		seen[v] = struct{}{}
+		lookingFor = append(lookingFor, v)
+	}
+	return lookingFor
+}
+
 func ValidateLookingFor(values []string) error {
 	for _, v := range values {
 		lf := LookingFor(v)
@@ -54,3 +290,104 @@ func ValidateLookingFor(values []string) error {
 	}
 	return nil
 }
+
+// NormalizeCurrency trims c and upper-cases it, so "usd" is stored as "USD".
+func NormalizeCurrency(c string) string {
+	return strings.ToUpper(strings.TrimSpace(c))
+}
+
+// ValidateCurrency checks that c is one of the supported ISO 4217 codes.
+func ValidateCurrency(c string) error {
+	if _, ok := supportedCurrencies[c]; !ok {
+		return fmt.Errorf("unsupported currency: %q", c)
+	}
+	return nil
+}
+
+// ValidateVisibility checks that v is one of the supported visibility values.
+func ValidateVisibility(v Visibility) error {
+	switch v {
+	case VisibilityPublic, VisibilityUnlisted, VisibilityPrivate:
+		return nil
+	}
+	return fmt.Errorf("invalid visibility value: %q", v)
+}
+
+// NormalizeIndustries cleans up whitespace in each industry and drops empty values and
+// case-insensitive duplicates, keeping the first spelling and the original order.
+func NormalizeIndustries(values []string) []string {
+	seen := make(map[string]struct{}, len(values))
+	industries := []string{}
+	for _, v := range values {
+		v = NormalizeWhitespace(v)
+		key := strings.ToLower(v)
+		if _, dup := seen[key]; v == "" || dup {
+			continue
+		}
+		seen[key] = struct{}{}
+		industries = append(industries, v)
+	}
+	return industries
+}
+
+// ValidateIndustries checks a normalized industry list.
+func ValidateIndustries(values []string) error {
+	if len(values) > MaxIndustries {
+		return fmt.Errorf("too many industries: %d, at most %d are allowed", len(values), MaxIndustries)
+	}
+	for _, v := range values {
+		if len(v) > maxIndustryLength {
+			return fmt.Errorf("industry %q is longer than %d characters", v, maxIndustryLength)
+		}
+	}
+	return nil
+}
+
+// ValidateGithubLink checks that link is an absolute http(s) URL. When allowedHosts is
+// non-empty the host must also match one of them (or be a subdomain of one).
+func ValidateGithubLink(link string, allowedHosts []string) error {
+	u, err := url.Parse(link)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid github_link %q: must be an absolute http or https URL", link)
+	}
+
+	if len(allowedHosts) == 0 {
+		return nil
+	}
+
+	host := strings.ToLower(u.Hostname())
+	for _, allowed := range allowedHosts {
+		allowed = strings.ToLower(allowed)
+		if host == allowed || strings.HasSuffix(host, "."+allowed) {
+			return nil
+		}
+	}
+	return fmt.Errorf("github_link host %q is not allowed; allowed hosts: %s", host, strings.Join(allowedHosts, ", "))
+}
+
+// NormalizeGithubLink puts link in canonical form: trimmed, with a lowercase host and no
+// trailing slash. Links that don't parse are returned trimmed, for validation to reject.
+func NormalizeGithubLink(link string) string {
+	link = strings.TrimSpace(link)
+	u, err := url.Parse(link)
+	if err != nil || u.Host == "" {
+		return link
+	}
+	u.Host = strings.ToLower(u.Host)
+	u.Path = strings.TrimRight(u.Path, "/")
+	u.RawPath = strings.TrimRight(u.RawPath, "/")
+	return u.String()
+}
+
+// NormalizeWhitespace trims s and collapses runs of internal whitespace into a single space.
+func NormalizeWhitespace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// Normalize cleans up user-supplied display fields in place.
+func (p *Project) Normalize() {
+	p.Title = NormalizeWhitespace(p.Title)
+	p.Subtitle = NormalizeWhitespace(p.Subtitle)
+	p.GithubLink = NormalizeGithubLink(p.GithubLink)
+	p.Currency = NormalizeCurrency(p.Currency)
+}
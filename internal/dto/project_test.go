@@ -0,0 +1,54 @@
+package dto
+
+import "testing"
+
+func TestValidateGithubLink(t *testing.T) {
+	github := []string{"github.com"}
+
+	tests := []struct {
+		name    string
+		link    string
+		allowed []string
+		wantErr bool
+	}{
+		{"repo", "https://github.com/acme/repo", github, false},
+		{"http", "http://github.com/acme/repo", github, false},
+		{"subdomain", "https://gist.github.com/acme/1234", github, false},
+		{"host case", "https://GitHub.com/acme/repo", github, false},
+		{"any host when unrestricted", "https://gitlab.com/acme/repo", nil, false},
+		{"other host", "https://gitlab.com/acme/repo", github, true},
+		{"lookalike host", "https://evilgithub.com/acme/repo", github, true},
+		{"allowed host as subdomain", "https://github.com.evil.example/acme", github, true},
+		{"scheme typo", "htp://github.com/acme/repo", github, true},
+		{"no scheme", "github.com/acme/repo", github, true},
+		{"javascript", "javascript:alert(1)", nil, true},
+		{"no host", "https:///acme/repo", nil, true},
+		{"empty", "", nil, true},
+		{"unparseable", "https://github.com/%zz", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateGithubLink(tt.link, tt.allowed)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateGithubLink(%q) error = %v, wantErr %v", tt.link, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNormalizeGithubLink(t *testing.T) {
+	tests := []struct {
+		link string
+		want string
+	}{
+		{" https://GitHub.com/acme/repo/ ", "https://github.com/acme/repo"},
+		{"https://github.com/acme/repo", "https://github.com/acme/repo"},
+		{"https://github.com/", "https://github.com"},
+		{"  not a url ", "not a url"},
+	}
+	for _, tt := range tests {
+		if got := NormalizeGithubLink(tt.link); got != tt.want {
+			t.Errorf("NormalizeGithubLink(%q) = %q, want %q", tt.link, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package dto
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// TimeFormat is the wire format of every timestamp in API responses.
+const TimeFormat = time.RFC3339
+
+// Time is a timestamp that always serializes as RFC 3339 in UTC, whatever location
+// the value was read in. Use it for every timestamp field in a DTO so all endpoints
+// agree on the format. The zero value serializes as null.
+type Time struct {
+	time.Time
+}
+
+// NewTime wraps t, normalized to UTC.
+func NewTime(t time.Time) Time {
+	return Time{Time: t.UTC()}
+}
+
+func (t Time) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(t.UTC().Format(TimeFormat))
+}
+
+func (t *Time) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*t = Time{}
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	parsed, err := time.Parse(TimeFormat, s)
+	if err != nil {
+		return fmt.Errorf("invalid timestamp %q: must be RFC 3339", s)
+	}
+	*t = NewTime(parsed)
+	return nil
+}
+
+// Scan implements sql.Scanner so DATETIME/TIMESTAMP columns can be scanned directly.
+// The driver must be configured with parseTime=true. NULL scans to the zero value.
+func (t *Time) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*t = Time{}
+	case time.Time:
+		*t = NewTime(v)
+	default:
+		return fmt.Errorf("cannot scan %T into dto.Time", src)
+	}
+	return nil
+}
+
+// Value implements driver.Valuer, storing the timestamp in UTC.
+func (t Time) Value() (driver.Value, error) {
+	if t.IsZero() {
+		return nil, nil
+	}
+	return t.UTC(), nil
+}
@@ -0,0 +1,72 @@
+package dto
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestTimeMarshalJSON(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*60*60)
+
+	tests := []struct {
+		name string
+		in   Time
+		want string
+	}{
+		{"utc", NewTime(time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)), `"2024-03-01T12:30:00Z"`},
+		{"converted to utc", Time{time.Date(2024, 3, 1, 21, 30, 0, 0, tokyo)}, `"2024-03-01T12:30:00Z"`},
+		{"sub-second dropped", NewTime(time.Date(2024, 3, 1, 12, 30, 0, 999, time.UTC)), `"2024-03-01T12:30:00Z"`},
+		{"zero is null", Time{}, `null`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.in)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Marshal = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimeUnmarshalJSON(t *testing.T) {
+	var got Time
+	if err := json.Unmarshal([]byte(`"2024-03-01T21:30:00+09:00"`), &got); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	want := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+	if !got.Equal(want) || got.Location() != time.UTC {
+		t.Errorf("Unmarshal = %v, want %v in UTC", got.Time, want)
+	}
+
+	if err := json.Unmarshal([]byte(`null`), &got); err != nil || !got.IsZero() {
+		t.Errorf("Unmarshal(null) = %v, %v; want the zero value", got.Time, err)
+	}
+
+	for _, bad := range []string{`"2024-03-01 12:30:00"`, `"yesterday"`, `1709296200`} {
+		if err := json.Unmarshal([]byte(bad), &got); err == nil {
+			t.Errorf("Unmarshal(%s) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestTimeScan(t *testing.T) {
+	var got Time
+	src := time.Date(2024, 3, 1, 21, 30, 0, 0, time.FixedZone("JST", 9*60*60))
+	if err := got.Scan(src); err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if !got.Equal(src) || got.Location() != time.UTC {
+		t.Errorf("Scan = %v, want %v in UTC", got.Time, src)
+	}
+
+	if err := got.Scan(nil); err != nil || !got.IsZero() {
+		t.Errorf("Scan(nil) = %v, %v; want the zero value", got.Time, err)
+	}
+	if err := got.Scan("2024-03-01"); err == nil {
+		t.Error("Scan(string) succeeded, want an error")
+	}
+}
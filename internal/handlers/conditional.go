@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+)
+
+// notModified sets Last-Modified from lastModified and answers 304 Not Modified when the
+// request's If-Modified-Since is not older than it. It reports whether the response was
+// written. A zero lastModified (e.g. an empty list) disables the check.
+//
+// This is a best-effort optimization for polling clients, not a strict validator: the
+// timestamp is the newest updated_at in the collection, so deletions, changes to child
+// rows that don't touch the parent, and edits within the same second go unnoticed.
+// Clients that need exact results should not send If-Modified-Since.
+func notModified(w http.ResponseWriter, r *http.Request, lastModified time.Time) bool {
+	if lastModified.IsZero() {
+		return false
+	}
+
+	// HTTP dates have one-second resolution.
+	lastModified = lastModified.UTC().Truncate(time.Second)
+	w.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil || lastModified.After(since) {
+		return false
+	}
+
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	_ "embed"
+	"log"
+	"net/http"
+)
+
+// openAPISpec describes every route. It is maintained by hand, so update it along with
+// the router and the DTOs.
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+// swaggerUIVersion pins the Swagger UI release the docs page loads from the CDN.
+const swaggerUIVersion = "5.17.14"
+
+// swaggerUIPage renders /openapi.json with Swagger UI.
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>Project module API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@` + swaggerUIVersion + `/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@` + swaggerUIVersion + `/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({ url: "/openapi.json", dom_id: "#swagger-ui" });
+  </script>
+</body>
+</html>
+`
+
+// DocsHandler serves the OpenAPI 3 spec of the API and a Swagger UI page to browse it.
+type DocsHandler struct{}
+
+func NewDocsHandler() *DocsHandler {
+	return &DocsHandler{}
+}
+
+// OpenAPI serves the spec as JSON.
+func (h *DocsHandler) OpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(openAPISpec); err != nil {
+		log.Println("Failed to write response:", err)
+	}
+}
+
+// SwaggerUI serves an HTML page rendering the spec with Swagger UI.
+func (h *DocsHandler) SwaggerUI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if _, err := w.Write([]byte(swaggerUIPage)); err != nil {
+		log.Println("Failed to write response:", err)
+	}
+}
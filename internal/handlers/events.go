@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/tarsuniversecentral/project-module/internal/dto"
+	"github.com/tarsuniversecentral/project-module/internal/middleware"
+	service "github.com/tarsuniversecentral/project-module/internal/services"
+	"github.com/tarsuniversecentral/project-module/pkg/utils"
+)
+
+// StreamProjectEvents streams the project's events as Server-Sent Events until the client
+// disconnects or the project is removed. Private projects can only be streamed by their
+// owner. Each event is sent with its type as the SSE event name and the JSON-encoded
+// dto.ProjectEvent as data. Idle streams get a comment line every heartbeat interval so
+// proxies don't close them.
+func (h *ProjectHandler) StreamProjectEvents(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid project ID", utils.ErrCodeBadRequest)
+		return
+	}
+
+	userID, _ := middleware.UserIDFromContext(r.Context())
+	sub, err := h.projectService.SubscribeEvents(r.Context(), id, userID)
+	if err != nil {
+		var limitErr *service.SubscriberLimitError
+		switch {
+		case errors.Is(err, service.ErrProjectNotFound):
+			utils.WriteJSONError(w, http.StatusNotFound, err.Error(), utils.ErrCodeNotFound)
+		case errors.As(err, &limitErr):
+			utils.WriteRetryableError(w, http.StatusServiceUnavailable, "Too many event subscribers, try again later", utils.ErrCodeUnavailable, limitErr.RetryAfter)
+		default:
+			log.Printf("Error subscribing to events of project %d: %v", id, err)
+			utils.WriteJSONError(w, http.StatusInternalServerError, "Internal Server Error", utils.ErrCodeInternal)
+		}
+		return
+	}
+	defer sub.Close()
+
+	// The stream outlives the server's write timeout, so lift it for this response.
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		log.Printf("Error clearing write deadline for project %d events: %v", id, err)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // Disable proxy buffering (nginx).
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		log.Printf("Streaming not supported for project %d events: %v", id, err)
+		return
+	}
+
+	heartbeat := time.NewTicker(h.projectService.EventHeartbeatInterval())
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+
+		case event := <-sub.Events:
+			data, err := json.Marshal(event)
+			if err != nil {
+				log.Printf("Error encoding %s event for project %d: %v", event.Type, id, err)
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+				return
+			}
+			if event.Type == dto.ProjectEventRemoved {
+				rc.Flush()
+				return
+			}
+		}
+
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	service "github.com/tarsuniversecentral/project-module/internal/services"
+	"github.com/tarsuniversecentral/project-module/pkg/utils"
+)
+
+// readyTimeout bounds each readiness check so a hung database can't stall the probe.
+const readyTimeout = 2 * time.Second
+
+// HealthHandler serves the liveness and readiness probes.
+type HealthHandler struct {
+	db           *sql.DB
+	fileService  *service.FileService
+	checkStorage bool
+	retryAfter   time.Duration
+}
+
+// NewHealthHandler creates the probe handler. When checkStorage is set, readiness also
+// requires upload storage to be writable. retryAfter is advertised when not ready.
+func NewHealthHandler(db *sql.DB, fileService *service.FileService, checkStorage bool, retryAfter time.Duration) *HealthHandler {
+	return &HealthHandler{db: db, fileService: fileService, checkStorage: checkStorage, retryAfter: retryAfter}
+}
+
+type checkResult struct {
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+type readinessResponse struct {
+	Status string                 `json:"status"`
+	Checks map[string]checkResult `json:"checks"`
+}
+
+// Healthz reports that the process is up. It doesn't touch any dependency.
+func (h *HealthHandler) Healthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"status":"ok"}` + "\n"))
+}
+
+// Readyz reports whether the service can handle traffic: the database must answer a ping
+// and, if enabled, upload storage must be writable. It returns 503 otherwise.
+func (h *HealthHandler) Readyz(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), readyTimeout)
+	defer cancel()
+
+	resp := readinessResponse{Status: "ok", Checks: make(map[string]checkResult)}
+
+	resp.Checks["database"] = runCheck(func() error { return h.db.PingContext(ctx) })
+	if h.checkStorage {
+		resp.Checks["storage"] = runCheck(func() error { return h.fileService.CheckStorage(ctx) })
+	}
+
+	status := http.StatusOK
+	for name, check := range resp.Checks {
+		if check.Status != "ok" {
+			log.Printf("Readiness check %s failed: %s", name, check.Error)
+			resp.Status = "unavailable"
+			status = http.StatusServiceUnavailable
+		}
+	}
+
+	if status == http.StatusServiceUnavailable {
+		utils.SetRetryAfter(w, h.retryAfter)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Println("Failed to write response:", err)
+	}
+}
+
+// runCheck times a single dependency check.
+func runCheck(check func() error) checkResult {
+	start := time.Now()
+	err := check()
+	result := checkResult{
+		Status:    "ok",
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = "error"
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// MetricsHandler serves Prometheus metrics from a registry of its own, which holds the
+// Go runtime and process metrics, the primary database's open connections, and whatever
+// the router registers on top.
+type MetricsHandler struct {
+	registry *prometheus.Registry
+	handler  http.Handler
+}
+
+// NewMetricsHandler creates the registry and the handler exposing it.
+func NewMetricsHandler(db *sql.DB) *MetricsHandler {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "db_open_connections",
+			Help: "Open connections to the primary database, both in use and idle.",
+		}, func() float64 {
+			return float64(db.Stats().OpenConnections)
+		}),
+	)
+
+	return &MetricsHandler{
+		registry: registry,
+		handler:  promhttp.HandlerFor(registry, promhttp.HandlerOpts{}),
+	}
+}
+
+// Registry returns the registry served by the handler.
+func (h *MetricsHandler) Registry() prometheus.Registerer {
+	return h.registry
+}
+
+// Metrics serves the metrics in the Prometheus exposition format.
+func (h *MetricsHandler) Metrics(w http.ResponseWriter, r *http.Request) {
+	h.handler.ServeHTTP(w, r)
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/tarsuniversecentral/project-module/internal/dto"
+)
+
+// parsePagination reads the page and page_size query parameters, applying the
+// defaults and rejecting values outside the allowed range.
+func parsePagination(r *http.Request) (int, int, error) {
+	query := r.URL.Query()
+
+	page := 1
+	if val := query.Get("page"); val != "" {
+		parsed, err := strconv.Atoi(val)
+		if err != nil || parsed < 1 {
+			return 0, 0, fmt.Errorf("invalid page %q: must be a positive integer", val)
+		}
+		page = parsed
+	}
+
+	perPage := dto.DefaultPerPage
+	if val := query.Get("page_size"); val != "" {
+		parsed, err := strconv.Atoi(val)
+		if err != nil || parsed < 1 || parsed > dto.MaxPerPage {
+			return 0, 0, fmt.Errorf("invalid page_size %q: must be between 1 and %d", val, dto.MaxPerPage)
+		}
+		perPage = parsed
+	}
+
+	return page, perPage, nil
+}
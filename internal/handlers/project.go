@@ -2,19 +2,24 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"mime"
+	"mime/multipart"
 	"net/http"
+	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
 
 	"github.com/gorilla/mux"
-	"golang.org/x/exp/rand"
 
 	"github.com/tarsuniversecentral/project-module/internal/dto"
+	"github.com/tarsuniversecentral/project-module/internal/middleware"
 	service "github.com/tarsuniversecentral/project-module/internal/services"
+	"github.com/tarsuniversecentral/project-module/pkg/utils"
 )
 
 type ProjectHandler struct {
@@ -22,15 +27,25 @@ type ProjectHandler struct {
 	fileService    *service.FileService
 }
 
-func NewProjectHandler(service *service.ProjectService) *ProjectHandler {
-	return &ProjectHandler{projectService: service}
+func NewProjectHandler(projectService *service.ProjectService, fileService *service.FileService) *ProjectHandler {
+	return &ProjectHandler{projectService: projectService, fileService: fileService}
 }
 
 func (h *ProjectHandler) CreateProject(w http.ResponseWriter, r *http.Request) {
 
+	// Stop reading bodies that can't fit within the upload limits.
+	if limit := h.fileService.MaxRequestBodyBytes(); limit > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, limit)
+	}
+
 	// Set a memory threshold of 10 MB
 	if err := r.ParseMultipartForm(10 << 20); err != nil {
-		http.Error(w, "Error parsing multipart form: "+err.Error(), http.StatusBadRequest)
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			utils.WriteJSONError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("Request body exceeds the %d byte limit", maxBytesErr.Limit), utils.ErrCodePayloadTooLarge)
+			return
+		}
+		utils.WriteJSONError(w, http.StatusBadRequest, "Error parsing multipart form: "+err.Error(), utils.ErrCodeBadRequest)
 		return
 	}
 	// Extracting form values
@@ -38,23 +53,29 @@ func (h *ProjectHandler) CreateProject(w http.ResponseWriter, r *http.Request) {
 		Title:       r.FormValue("title"),
 		Subtitle:    r.FormValue("subtitle"),
 		Industry:    r.FormValue("industry"),
+		Industries:  r.Form["industries"],
 		Description: r.FormValue("description"),
+		LookingFor:  r.Form["looking_for"],
 		GithubLink:  r.FormValue("github_link"),
+		Visibility:  dto.Visibility(r.FormValue("visibility")),
+		Currency:    r.FormValue("currency"),
+	}
+	// The route requires a user, who becomes the project's owner.
+	if userID, ok := middleware.UserIDFromContext(r.Context()); ok {
+		project.OwnerID = userID
 	}
 
 	if val := r.FormValue("project_value"); val != "" {
 		parsedValue, err := strconv.ParseFloat(val, 64)
 		if err != nil {
-			http.Error(w, "Invalid project_value format", http.StatusBadRequest)
+			utils.WriteJSONError(w, http.StatusBadRequest, "Invalid project_value format", utils.ErrCodeBadRequest)
 			return
 		}
 		project.ProjectValue = parsedValue
 	}
 
-	project.LookingFor = r.Form["looking_for"]
-
-	if err := dto.ValidateLookingFor(project.LookingFor); err != nil {
-		http.Error(w, "Error validate looking_for: "+err.Error(), http.StatusBadRequest)
+	if err := h.projectService.ValidateProject(&project); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, err.Error(), utils.ErrCodeValidation)
 		return
 	}
 
@@ -62,64 +83,420 @@ func (h *ProjectHandler) CreateProject(w http.ResponseWriter, r *http.Request) {
 	pdfHeaders := r.MultipartForm.File["pdfs"]
 	imageHeaders := r.MultipartForm.File["images"]
 
+	// Reject the upload up front if it would exceed the client's byte quota.
+	clientIP := middleware.ClientIP(r)
+	uploadBytes := totalUploadSize(pdfHeaders, imageHeaders)
+	if ok, retryAfter := h.fileService.CheckUploadQuota(clientIP, uploadBytes); !ok {
+		utils.WriteRetryableError(w, http.StatusTooManyRequests, "Upload quota exceeded", utils.ErrCodeQuotaExceeded, retryAfter)
+		return
+	}
+
 	// Process the file uploads concurrently in the service layer.
-	fileResponse, err := h.fileService.ProcessUploads(pdfHeaders, imageHeaders)
+	fileResponse, err := h.fileService.ProcessUploads(r.Context(), pdfHeaders, imageHeaders)
 	if err != nil {
-		http.Error(w, "Internal Server Error: "+err.Error(), http.StatusInternalServerError)
+		switch {
+		case errors.Is(err, service.ErrStorageFull):
+			log.Printf("Upload storage is full: %v", err)
+			utils.WriteJSONError(w, http.StatusInsufficientStorage, "Insufficient storage to save the uploaded files", utils.ErrCodeInsufficientStorage)
+		case errors.Is(err, service.ErrFileTooLarge):
+			utils.WriteJSONError(w, http.StatusRequestEntityTooLarge, err.Error(), utils.ErrCodePayloadTooLarge)
+		case errors.Is(err, service.ErrInvalidFileType):
+			utils.WriteJSONError(w, http.StatusUnsupportedMediaType, err.Error(), utils.ErrCodeUnsupportedMediaType)
+		case errors.Is(err, service.ErrValidation):
+			utils.WriteJSONError(w, http.StatusBadRequest, err.Error(), utils.ErrCodeValidation)
+		default:
+			utils.WriteJSONError(w, http.StatusInternalServerError, "Internal Server Error: "+err.Error(), utils.ErrCodeInternal)
+		}
 		return
 	}
+	h.fileService.RecordUpload(clientIP, uploadBytes)
 
 	project.PitchDecks = fileResponse.PDFFiles
+	project.PitchDeckPreviews = fileResponse.PDFPreviews
 	project.Images = fileResponse.ImageFiles
+	project.OriginalNames = fileResponse.OriginalNames
 
-	resProject, err := h.projectService.CreateProject(project)
+	resProject, err := h.projectService.CreateProject(r.Context(), project)
 	if err != nil {
 		delErr := h.fileService.DeleteSavedFiles(dto.ConstructFileResults(fileResponse))
 		if delErr != nil {
 			combinedError := fmt.Errorf("project creation error: %v; file deletion error: %v", err, delErr)
 			log.Printf("Internal server error: %v", combinedError)
-			http.Error(w, combinedError.Error(), http.StatusInternalServerError)
+			utils.WriteJSONError(w, http.StatusInternalServerError, combinedError.Error(), utils.ErrCodeInternal)
+			return
+
+		}
+		if errors.Is(err, service.ErrValidation) {
+			utils.WriteJSONError(w, http.StatusBadRequest, err.Error(), utils.ErrCodeValidation)
+			return
+		}
+		utils.WriteJSONError(w, http.StatusInternalServerError, err.Error(), utils.ErrCodeInternal)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", fmt.Sprintf("%s/projects/%d", dto.APIBasePath, resProject.ID))
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(resProject); err != nil {
+		log.Println("Failed to write response:", err)
+	}
+}
+
+// totalUploadSize sums the sizes of all uploaded file parts.
+func totalUploadSize(headerSets ...[]*multipart.FileHeader) int64 {
+	var total int64
+	for _, headers := range headerSets {
+		for _, header := range headers {
+			total += header.Size
+		}
+	}
+	return total
+}
+
+// UpdateProject applies a partial update. See dto.ProjectPatch for the absent/null/value semantics.
+func (h *ProjectHandler) UpdateProject(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid project ID", utils.ErrCodeBadRequest)
+		return
+	}
+
+	var patch dto.ProjectPatch
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&patch); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid request body: "+err.Error(), utils.ErrCodeBadRequest)
+		return
+	}
+
+	userID, ok := userIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	project, err := h.projectService.UpdateProject(r.Context(), id, userID, patch)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrValidation):
+			utils.WriteJSONError(w, http.StatusBadRequest, err.Error(), utils.ErrCodeValidation)
+		case errors.Is(err, service.ErrForbidden):
+			utils.WriteJSONError(w, http.StatusForbidden, err.Error(), utils.ErrCodeForbidden)
+		case errors.Is(err, service.ErrProjectNotFound):
+			utils.WriteJSONError(w, http.StatusNotFound, err.Error(), utils.ErrCodeNotFound)
+		default:
+			utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to update project", utils.ErrCodeInternal)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(project); err != nil {
+		log.Println("Failed to write response:", err)
+	}
+}
+
+// ImportGithubProject returns a draft project prefilled from a GitHub repository without saving it.
+func (h *ProjectHandler) ImportGithubProject(w http.ResponseWriter, r *http.Request) {
+	var requestBody struct {
+		RepoURL string `json:"repo_url"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid request body", utils.ErrCodeBadRequest)
+		return
+	}
+
+	if requestBody.RepoURL == "" {
+		utils.WriteJSONError(w, http.StatusBadRequest, "repo_url cannot be empty", utils.ErrCodeBadRequest)
+		return
+	}
+
+	draft, err := h.projectService.ImportFromGithub(requestBody.RepoURL)
+	if err != nil {
+		var rateErr *service.GithubRateLimitError
+		switch {
+		case errors.Is(err, service.ErrValidation):
+			utils.WriteJSONError(w, http.StatusBadRequest, err.Error(), utils.ErrCodeValidation)
+		case errors.Is(err, service.ErrGithubRepoNotFound):
+			utils.WriteJSONError(w, http.StatusNotFound, "Repository not found or private", utils.ErrCodeNotFound)
+		case errors.As(err, &rateErr):
+			utils.WriteRetryableError(w, http.StatusServiceUnavailable, "GitHub rate limit exceeded, try again later", utils.ErrCodeUnavailable, rateErr.RetryAfter)
+		default:
+			log.Println("Error importing GitHub repository:", err)
+			utils.WriteJSONError(w, http.StatusBadGateway, "Failed to import from GitHub", utils.ErrCodeUpstream)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(draft); err != nil {
+		log.Println("Failed to write response:", err)
+	}
+}
+
+func (h *ProjectHandler) ListProjects(w http.ResponseWriter, r *http.Request) {
+	page, perPage, err := parsePagination(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, err.Error(), utils.ErrCodeBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := dto.ProjectFilter{
+		Industries: query["industry"],
+		LookingFor: query["looking_for"],
+	}
+
+	projects, err := h.projectService.ListProjects(r.Context(), filter, page, perPage, query.Get("sort"))
+	if err != nil {
+		if errors.Is(err, service.ErrValidation) {
+			utils.WriteJSONError(w, http.StatusBadRequest, err.Error(), utils.ErrCodeValidation)
 			return
+		}
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to fetch projects", utils.ErrCodeInternal)
+		return
+	}
+
+	if notModified(w, r, projects.LastModified) {
+		return
+	}
 
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(projects); err != nil {
+		log.Println("Failed to write response:", err)
+	}
+}
+
+// ListMyProjects lists the authenticated user's own projects, including unlisted and private
+// ones, with the same pagination and sort parameters as ListProjects.
+func (h *ProjectHandler) ListMyProjects(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	page, perPage, err := parsePagination(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, err.Error(), utils.ErrCodeBadRequest)
+		return
+	}
+
+	projects, err := h.projectService.ListProjectsByOwner(r.Context(), userID, page, perPage, r.URL.Query().Get("sort"))
+	if err != nil {
+		if errors.Is(err, service.ErrValidation) {
+			utils.WriteJSONError(w, http.StatusBadRequest, err.Error(), utils.ErrCodeValidation)
+			return
 		}
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to fetch projects", utils.ErrCodeInternal)
+		return
+	}
+
+	if notModified(w, r, projects.LastModified) {
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(resProject)
+	if err := json.NewEncoder(w).Encode(projects); err != nil {
+		log.Println("Failed to write response:", err)
+	}
+}
+
+// CountProjects returns the number of projects matching the same industry and looking_for
+// filters as ListProjects, without fetching a page.
+func (h *ProjectHandler) CountProjects(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := dto.ProjectFilter{
+		Industries: query["industry"],
+		LookingFor: query["looking_for"],
+	}
+
+	total, err := h.projectService.CountProjects(r.Context(), filter)
+	if err != nil {
+		if errors.Is(err, service.ErrValidation) {
+			utils.WriteJSONError(w, http.StatusBadRequest, err.Error(), utils.ErrCodeValidation)
+			return
+		}
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to count projects", utils.ErrCodeInternal)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(dto.CountResponse{Total: total}); err != nil {
+		log.Println("Failed to write response:", err)
+	}
+}
+
+// ListIndustries responds with the sorted industries public projects list, as a JSON array
+// of strings.
+func (h *ProjectHandler) ListIndustries(w http.ResponseWriter, r *http.Request) {
+	industries, err := h.projectService.Industries(r.Context())
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to fetch industries", utils.ErrCodeInternal)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(industries); err != nil {
+		log.Println("Failed to write response:", err)
+	}
+}
+
+func (h *ProjectHandler) GetValueByIndustry(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.projectService.ValueByIndustry(r.Context())
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to fetch project value stats", utils.ErrCodeInternal)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(stats); err != nil {
+		log.Println("Failed to write response:", err)
+	}
 }
 
 func (h *ProjectHandler) GetProject(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id, err := strconv.Atoi(vars["id"])
 	if err != nil {
-		http.Error(w, "Invalid project ID", http.StatusBadRequest)
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid project ID", utils.ErrCodeBadRequest)
 		return
 	}
 
-	project, err := h.projectService.GetProject(id)
+	// Anonymous callers are user 0; owners can also read their private projects.
+	userID, _ := middleware.UserIDFromContext(r.Context())
+	previewToken := r.URL.Query().Get("preview_token")
+	project, err := h.projectService.GetProject(r.Context(), id, userID, previewToken)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		if errors.Is(err, service.ErrProjectNotFound) {
+			utils.WriteJSONError(w, http.StatusNotFound, err.Error(), utils.ErrCodeNotFound)
+			return
+		}
+		log.Printf("Error fetching project %d: %v", id, err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to fetch project", utils.ErrCodeInternal)
 		return
 	}
 
-	project.LikeCount = rand.Intn(100)
-	project.CommentCount = rand.Intn(45)
-	project.ViewCount = rand.Intn(1000)
-	project.Verified = rand.Intn(2) == 1
+	// Keep draft previews and private projects out of shared caches.
+	if previewToken != "" || project.Visibility == dto.VisibilityPrivate {
+		w.Header().Set("Cache-Control", "private, no-store")
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(project)
 }
 
+// DeleteFile removes a stored file that no project references, e.g. a mistaken upload.
+func (h *ProjectHandler) DeleteFile(w http.ResponseWriter, r *http.Request) {
+	filename, ok := filenameParam(r)
+	if !ok {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid filename", utils.ErrCodeValidation)
+		return
+	}
+
+	if err := h.projectService.CheckFileUnreferenced(r.Context(), filename); err != nil {
+		if errors.Is(err, service.ErrFileInUse) {
+			utils.WriteJSONError(w, http.StatusConflict, err.Error(), utils.ErrCodeConflict)
+			return
+		}
+		log.Printf("Error checking references to file %s: %v", filename, err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to delete file", utils.ErrCodeInternal)
+		return
+	}
+
+	if err := h.fileService.DeleteFile(filename); err != nil {
+		switch {
+		case errors.Is(err, service.ErrValidation):
+			utils.WriteJSONError(w, http.StatusBadRequest, err.Error(), utils.ErrCodeValidation)
+		case errors.Is(err, service.ErrFileNotFound):
+			utils.WriteJSONError(w, http.StatusNotFound, err.Error(), utils.ErrCodeNotFound)
+		default:
+			log.Printf("Error deleting file %s: %v", filename, err)
+			utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to delete file", utils.ErrCodeInternal)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// fileETag returns a weak validator built from the file's size and modification time.
+// Stored files are never rewritten in place, so this changes whenever the content does.
+func fileETag(info os.FileInfo) string {
+	return fmt.Sprintf(`W/"%x-%x"`, info.Size(), info.ModTime().UnixNano())
+}
+
+// contentDisposition offers the file under the name it was uploaded with, falling back to
+// the stored name. Non-ASCII names are encoded as RFC 2231 filename* parameters.
+func contentDisposition(storedName, originalName string) string {
+	if originalName != "" {
+		if v := mime.FormatMediaType("inline", map[string]string{"filename": originalName}); v != "" {
+			return v
+		}
+	}
+	return fmt.Sprintf("inline; filename=\"%s\"", storedName)
+}
+
+// userIDParam returns the authenticated user's ID, answering 401 when the request has none.
+func userIDParam(w http.ResponseWriter, r *http.Request) (int, bool) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "Unauthorized", utils.ErrCodeUnauthorized)
+	}
+	return userID, ok
+}
+
+// filenameParam returns the {filename} route variable, sanitized. Names with path
+// separators or ".." are refused outright rather than cleaned up, since no stored file
+// has them; ok is false for those and for names that sanitize to nothing.
+func filenameParam(r *http.Request) (filename string, ok bool) {
+	raw := mux.Vars(r)["filename"]
+	if utils.HasPathElements(raw) {
+		return "", false
+	}
+	filename = utils.SanitizeFilename(raw)
+	return filename, filename != ""
+}
+
 func (h *ProjectHandler) FileRetrieveHandler(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	filename := vars["filename"]
+	// Everything below, including the content type, works from the sanitized name.
+	filename, ok := filenameParam(r)
+	if !ok {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid filename", utils.ErrCodeValidation)
+		return
+	}
+
+	// Files of unlisted projects are served like the project itself; private ones only to
+	// their owner or with a preview token.
+	userID, _ := middleware.UserIDFromContext(r.Context())
+	ref, err := h.projectService.CheckFileAccess(r.Context(), filename, userID, r.URL.Query().Get("preview_token"))
+	if err != nil {
+		if errors.Is(err, service.ErrFileNotFound) {
+			utils.WriteJSONError(w, http.StatusNotFound, fmt.Sprintf("Error retrieving file: %v", err), utils.ErrCodeNotFound)
+			return
+		}
+		log.Printf("Error checking access to file %s: %v", filename, err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Internal Server Error", utils.ErrCodeInternal)
+		return
+	}
+
+	// Let the CDN serve the bytes when this visibility is offloaded.
+	if cdnURL, ok := h.fileService.CDNURL(filename, ref.Visibility); ok {
+		http.Redirect(w, r, cdnURL, http.StatusFound)
+		return
+	}
 
 	file, err := h.fileService.RetrieveFile(filename)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Error retrieving file: %v", err), http.StatusNotFound)
+		switch {
+		case errors.Is(err, service.ErrValidation):
+			utils.WriteJSONError(w, http.StatusBadRequest, err.Error(), utils.ErrCodeValidation)
+		case errors.Is(err, service.ErrFileNotFound):
+			utils.WriteJSONError(w, http.StatusNotFound, fmt.Sprintf("Error retrieving file: %v", err), utils.ErrCodeNotFound)
+		default:
+			log.Printf("Error retrieving file %s: %v", filename, err)
+			utils.WriteJSONError(w, http.StatusInternalServerError, "Internal Server Error", utils.ErrCodeInternal)
+		}
 		return
 	}
 	defer file.Close()
@@ -135,14 +512,37 @@ func (h *ProjectHandler) FileRetrieveHandler(w http.ResponseWriter, r *http.Requ
 		contentType = "image/png"
 	case ".svg":
 		contentType = "image/svg+xml"
+	case ".webp":
+		contentType = "image/webp"
+	case ".gif":
+		contentType = "image/gif"
 	default:
 		contentType = "application/octet-stream"
 	}
 
 	w.Header().Set("Content-Type", contentType)
-	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=\"%s\"", filename))
-	if _, err := io.Copy(w, file); err != nil {
-		http.Error(w, fmt.Sprintf("Error sending file: %v", err), http.StatusInternalServerError)
+	w.Header().Set("Content-Disposition", contentDisposition(filename, ref.OriginalName))
+
+	// Seekable files with a known size and modtime go through http.ServeContent, which
+	// answers conditional requests (If-None-Match, If-Modified-Since) with 304, serves
+	// ranges and sets Content-Length.
+	if seeker, ok := file.(interface {
+		io.ReadSeeker
+		Stat() (os.FileInfo, error)
+	}); ok {
+		if info, err := seeker.Stat(); err == nil {
+			w.Header().Set("ETag", fileETag(info))
+			http.ServeContent(w, r, filename, info.ModTime(), seeker)
+			return
+		}
+	}
+
+	if written, err := io.Copy(w, file); err != nil {
+		// The status and part of the body are already on the wire, so the response
+		// can't be turned into an error. Abort the connection instead so the client
+		// sees an incomplete transfer rather than a silently truncated file.
+		log.Printf("Error sending file %s after %d bytes, aborting response: %v", filename, written, err)
+		panic(http.ErrAbortHandler)
 	}
 }
 
@@ -152,26 +552,40 @@ func (h *ProjectHandler) AddTeamMemberToProject(w http.ResponseWriter, r *http.R
 	projectIdStr := vars["projectId"]
 	projectID, err := strconv.Atoi(projectIdStr)
 	if err != nil {
-		http.Error(w, "Invalid project ID", http.StatusBadRequest)
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid project ID", utils.ErrCodeBadRequest)
 		return
 	}
 
 	var member dto.TeamMember
 	if err := json.NewDecoder(r.Body).Decode(&member); err != nil {
-		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid request payload", utils.ErrCodeBadRequest)
 		return
 	}
 	// Set the project ID from the URL, ensuring consistency.
 	member.ProjectID = projectID
 
+	userID, ok := userIDParam(w, r)
+	if !ok {
+		return
+	}
+
 	// Insert the team member into the database.
-	if err := h.projectService.AddTeamMember(&member); err != nil {
-		http.Error(w, "Failed to insert team member", http.StatusInternalServerError)
+	if err := h.projectService.AddTeamMember(r.Context(), &member, userID); err != nil {
+		switch {
+		case errors.Is(err, service.ErrForbidden):
+			utils.WriteJSONError(w, http.StatusForbidden, err.Error(), utils.ErrCodeForbidden)
+		case errors.Is(err, service.ErrProjectNotFound):
+			utils.WriteJSONError(w, http.StatusNotFound, err.Error(), utils.ErrCodeNotFound)
+		default:
+			log.Printf("Error adding team member to project %d: %v", projectID, err)
+			utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to insert team member", utils.ErrCodeInternal)
+		}
 		return
 	}
 
 	// Return the inserted team member as a JSON response.
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", fmt.Sprintf("%s/projects/teammember/%d", dto.APIBasePath, member.ID))
 	w.WriteHeader(http.StatusCreated)
 	if err := json.NewEncoder(w).Encode(member); err != nil {
 		log.Println("Failed to write response:", err)
@@ -183,14 +597,30 @@ func (h *ProjectHandler) GetTeamMembersOfProject(w http.ResponseWriter, r *http.
 	projectIdStr := vars["projectId"]
 	projectID, err := strconv.Atoi(projectIdStr)
 	if err != nil {
-		http.Error(w, "Invalid project ID", http.StatusBadRequest)
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid project ID", utils.ErrCodeBadRequest)
+		return
+	}
+
+	page, perPage, err := parsePagination(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, err.Error(), utils.ErrCodeBadRequest)
 		return
 	}
 
 	// Retrieve the team members from the database.
-	members, err := h.projectService.GetTeamMembers(projectID)
+	userID, _ := middleware.UserIDFromContext(r.Context())
+	members, err := h.projectService.GetTeamMembers(r.Context(), projectID, userID, page, perPage)
 	if err != nil {
-		http.Error(w, "Failed to fetch team members", http.StatusInternalServerError)
+		if errors.Is(err, service.ErrProjectNotFound) {
+			utils.WriteJSONError(w, http.StatusNotFound, err.Error(), utils.ErrCodeNotFound)
+			return
+		}
+		log.Printf("Error fetching team members of project %d: %v", projectID, err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to fetch team members", utils.ErrCodeInternal)
+		return
+	}
+
+	if notModified(w, r, members.LastModified) {
 		return
 	}
 
@@ -201,35 +631,527 @@ func (h *ProjectHandler) GetTeamMembersOfProject(w http.ResponseWriter, r *http.
 	}
 }
 
+// GetTeamMember returns a single team member, e.g. to prefill an edit form.
+func (h *ProjectHandler) GetTeamMember(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	memberID, err := strconv.Atoi(vars["memberId"])
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid team member ID", utils.ErrCodeBadRequest)
+		return
+	}
+
+	userID, _ := middleware.UserIDFromContext(r.Context())
+	member, err := h.projectService.GetTeamMember(r.Context(), memberID, userID)
+	if err != nil {
+		if errors.Is(err, service.ErrTeamMemberNotFound) {
+			utils.WriteJSONError(w, http.StatusNotFound, err.Error(), utils.ErrCodeNotFound)
+			return
+		}
+		log.Printf("Error fetching team member %d: %v", memberID, err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to fetch team member", utils.ErrCodeInternal)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(member); err != nil {
+		log.Println("Failed to write response:", err)
+	}
+}
+
 func (h *ProjectHandler) UpdateTeamMemberRole(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	memberIDStr := vars["memberId"]
 	memberID, err := strconv.Atoi(memberIDStr)
 	if err != nil {
-		http.Error(w, "Invalid team member ID", http.StatusBadRequest)
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid team member ID", utils.ErrCodeBadRequest)
 		return
 	}
 
+	// Version must be the member's current version; it guards against overwriting someone
+	// else's concurrent edit.
 	var requestBody struct {
-		Role string `json:"role"`
+		Role    string `json:"role"`
+		Version int    `json:"version"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid request body", utils.ErrCodeBadRequest)
 		return
 	}
 
 	if requestBody.Role == "" {
-		http.Error(w, "Role cannot be empty", http.StatusBadRequest)
+		utils.WriteJSONError(w, http.StatusBadRequest, "Role cannot be empty", utils.ErrCodeBadRequest)
+		return
+	}
+
+	if requestBody.Version == 0 {
+		utils.WriteJSONError(w, http.StatusPreconditionRequired, "Version is required; send the member's version as last read", utils.ErrCodePreconditionRequired)
+		return
+	}
+
+	userID, ok := userIDParam(w, r)
+	if !ok {
 		return
 	}
 
 	// Update the role of the team member in the database.
-	err = h.projectService.UpdateTeamMemberRole(memberID, requestBody.Role)
+	err = h.projectService.UpdateTeamMemberRole(r.Context(), memberID, userID, requestBody.Role, requestBody.Version)
 	if err != nil {
-		http.Error(w, "Failed to update team member role", http.StatusInternalServerError)
+		switch {
+		case errors.Is(err, service.ErrValidation):
+			utils.WriteJSONError(w, http.StatusBadRequest, err.Error(), utils.ErrCodeValidation)
+		case errors.Is(err, service.ErrForbidden):
+			utils.WriteJSONError(w, http.StatusForbidden, err.Error(), utils.ErrCodeForbidden)
+		case errors.Is(err, service.ErrTeamMemberNotFound):
+			utils.WriteJSONError(w, http.StatusNotFound, err.Error(), utils.ErrCodeNotFound)
+		case errors.Is(err, service.ErrVersionConflict):
+			utils.WriteJSONError(w, http.StatusConflict, err.Error(), utils.ErrCodeConflict)
+		default:
+			utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to update team member role", utils.ErrCodeInternal)
+		}
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent) // Respond with no content on success.
 }
+
+func (h *ProjectHandler) DeleteTeamMember(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	memberID, err := strconv.Atoi(vars["memberId"])
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid team member ID", utils.ErrCodeBadRequest)
+		return
+	}
+
+	userID, ok := userIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.projectService.DeleteTeamMember(r.Context(), memberID, userID); err != nil {
+		switch {
+		case errors.Is(err, service.ErrForbidden):
+			utils.WriteJSONError(w, http.StatusForbidden, err.Error(), utils.ErrCodeForbidden)
+		case errors.Is(err, service.ErrTeamMemberNotFound):
+			utils.WriteJSONError(w, http.StatusNotFound, err.Error(), utils.ErrCodeNotFound)
+		default:
+			utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to delete team member", utils.ErrCodeInternal)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ReassignTeamMembers moves a batch of team members to another project.
+func (h *ProjectHandler) ReassignTeamMembers(w http.ResponseWriter, r *http.Request) {
+	var req dto.ReassignTeamMembersRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid request body: "+err.Error(), utils.ErrCodeBadRequest)
+		return
+	}
+
+	userID, ok := userIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	moved, err := h.projectService.ReassignTeamMembers(r.Context(), req, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrValidation):
+			utils.WriteJSONError(w, http.StatusBadRequest, err.Error(), utils.ErrCodeValidation)
+		case errors.Is(err, service.ErrProjectNotFound), errors.Is(err, service.ErrTeamMemberNotFound):
+			utils.WriteJSONError(w, http.StatusNotFound, err.Error(), utils.ErrCodeNotFound)
+		default:
+			utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to reassign team members", utils.ErrCodeInternal)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]int{"moved": moved}); err != nil {
+		log.Println("Failed to write response:", err)
+	}
+}
+
+// ReconcileCounts recomputes the projects' denormalized counts from the rows they count.
+func (h *ProjectHandler) ReconcileCounts(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	corrected, err := h.projectService.ReconcileCounts(r.Context(), userID)
+	if err != nil {
+		log.Printf("Error reconciling counts: %v", err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to reconcile counts", utils.ErrCodeInternal)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]int64{"corrected": corrected}); err != nil {
+		log.Println("Failed to write response:", err)
+	}
+}
+
+func (h *ProjectHandler) SetProjectLead(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectID, err := strconv.Atoi(vars["projectId"])
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid project ID", utils.ErrCodeBadRequest)
+		return
+	}
+
+	memberID, err := strconv.Atoi(vars["memberId"])
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid team member ID", utils.ErrCodeBadRequest)
+		return
+	}
+
+	userID, ok := userIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.projectService.SetProjectLead(r.Context(), projectID, memberID, userID); err != nil {
+		switch {
+		case errors.Is(err, service.ErrForbidden):
+			utils.WriteJSONError(w, http.StatusForbidden, err.Error(), utils.ErrCodeForbidden)
+		case errors.Is(err, service.ErrProjectNotFound), errors.Is(err, service.ErrTeamMemberNotFound):
+			utils.WriteJSONError(w, http.StatusNotFound, err.Error(), utils.ErrCodeNotFound)
+		default:
+			log.Printf("Error setting lead of project %d to member %d: %v", projectID, memberID, err)
+			utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to set project lead", utils.ErrCodeInternal)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SetPrimaryImage makes one of the project's images its cover, the thumbnail in lists.
+func (h *ProjectHandler) SetPrimaryImage(w http.ResponseWriter, r *http.Request) {
+	projectID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid project ID", utils.ErrCodeBadRequest)
+		return
+	}
+
+	filename, ok := filenameParam(r)
+	if !ok {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid filename", utils.ErrCodeValidation)
+		return
+	}
+
+	userID, ok := userIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.projectService.SetPrimaryImage(r.Context(), projectID, userID, filename); err != nil {
+		switch {
+		case errors.Is(err, service.ErrForbidden):
+			utils.WriteJSONError(w, http.StatusForbidden, err.Error(), utils.ErrCodeForbidden)
+		case errors.Is(err, service.ErrProjectNotFound), errors.Is(err, service.ErrFileNotFound):
+			utils.WriteJSONError(w, http.StatusNotFound, err.Error(), utils.ErrCodeNotFound)
+		default:
+			log.Printf("Error setting primary image %s of project %d: %v", filename, projectID, err)
+			utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to set primary image", utils.ErrCodeInternal)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeleteProjectImages removes every image of the project.
+func (h *ProjectHandler) DeleteProjectImages(w http.ResponseWriter, r *http.Request) {
+	h.deleteProjectFiles(w, r, dto.FileTypeImages)
+}
+
+// DeleteProjectPitchDecks removes every pitch deck PDF of the project.
+func (h *ProjectHandler) DeleteProjectPitchDecks(w http.ResponseWriter, r *http.Request) {
+	h.deleteProjectFiles(w, r, dto.FileTypePDFs)
+}
+
+func (h *ProjectHandler) deleteProjectFiles(w http.ResponseWriter, r *http.Request, fileType string) {
+	vars := mux.Vars(r)
+	projectID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid project ID", utils.ErrCodeBadRequest)
+		return
+	}
+
+	userID, ok := userIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	deleted, err := h.projectService.DeleteProjectFiles(r.Context(), projectID, userID, fileType)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrForbidden):
+			utils.WriteJSONError(w, http.StatusForbidden, err.Error(), utils.ErrCodeForbidden)
+		case errors.Is(err, service.ErrProjectNotFound):
+			utils.WriteJSONError(w, http.StatusNotFound, err.Error(), utils.ErrCodeNotFound)
+		default:
+			log.Printf("Error deleting %s of project %d: %v", fileType, projectID, err)
+			utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to delete project files", utils.ErrCodeInternal)
+		}
+		return
+	}
+
+	response := struct {
+		Deleted int    `json:"deleted"`
+		Warning string `json:"warning,omitempty"`
+	}{Deleted: len(deleted)}
+
+	// The rows are already gone, so a storage failure only leaves orphaned files behind.
+	if err := h.fileService.DeleteSavedFiles(deleted); err != nil {
+		log.Printf("Error deleting files for project %d: %v", projectID, err)
+		response.Warning = err.Error()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Println("Failed to write response:", err)
+	}
+}
+
+// DeleteProjectFile removes a single image or pitch deck from the project, along with the
+// deck's preview image.
+func (h *ProjectHandler) DeleteProjectFile(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid project ID", utils.ErrCodeBadRequest)
+		return
+	}
+
+	filename, ok := filenameParam(r)
+	if !ok {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid filename", utils.ErrCodeValidation)
+		return
+	}
+
+	userID, ok := userIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	deleted, err := h.projectService.DeleteProjectFile(r.Context(), projectID, userID, filename)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrForbidden):
+			utils.WriteJSONError(w, http.StatusForbidden, err.Error(), utils.ErrCodeForbidden)
+		case errors.Is(err, service.ErrProjectNotFound), errors.Is(err, service.ErrFileNotFound):
+			utils.WriteJSONError(w, http.StatusNotFound, err.Error(), utils.ErrCodeNotFound)
+		default:
+			log.Printf("Error deleting file %s from project %d: %v", filename, projectID, err)
+			utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to delete project file", utils.ErrCodeInternal)
+		}
+		return
+	}
+
+	// The row is already gone, so a storage failure only leaves an orphaned file behind.
+	if err := h.fileService.DeleteSavedFiles(deleted); err != nil {
+		log.Printf("Error deleting stored file %s of project %d: %v", filename, projectID, err)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *ProjectHandler) DeleteProject(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid project ID", utils.ErrCodeBadRequest)
+		return
+	}
+
+	userID, ok := userIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	files, err := h.projectService.DeleteProject(r.Context(), id, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrForbidden):
+			utils.WriteJSONError(w, http.StatusForbidden, err.Error(), utils.ErrCodeForbidden)
+		case errors.Is(err, service.ErrProjectNotFound):
+			utils.WriteJSONError(w, http.StatusNotFound, err.Error(), utils.ErrCodeNotFound)
+		default:
+			log.Printf("Error deleting project %d: %v", id, err)
+			utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to delete project", utils.ErrCodeInternal)
+		}
+		return
+	}
+
+	// The project is gone at this point, so a storage failure is reported as a warning.
+	if err := h.fileService.DeleteSavedFiles(dto.ConstructFileResults(files)); err != nil {
+		log.Printf("Error deleting files of project %d: %v", id, err)
+
+		response := struct {
+			Deleted     bool     `json:"deleted"`
+			Warning     string   `json:"warning"`
+			FailedFiles []string `json:"failed_files,omitempty"`
+		}{Deleted: true, Warning: "project deleted but some files could not be removed"}
+
+		var delErr *service.FileDeletionError
+		if errors.As(err, &delErr) {
+			response.FailedFiles = delErr.Files
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			log.Println("Failed to write response:", err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// LikeProject records the authenticated user's like of the project. Liking twice is a no-op.
+func (h *ProjectHandler) LikeProject(w http.ResponseWriter, r *http.Request) {
+	h.setProjectLike(w, r, true)
+}
+
+// UnlikeProject removes the authenticated user's like of the project, if any.
+func (h *ProjectHandler) UnlikeProject(w http.ResponseWriter, r *http.Request) {
+	h.setProjectLike(w, r, false)
+}
+
+func (h *ProjectHandler) setProjectLike(w http.ResponseWriter, r *http.Request, liked bool) {
+	vars := mux.Vars(r)
+	projectID, err := strconv.Atoi(vars["projectId"])
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid project ID", utils.ErrCodeBadRequest)
+		return
+	}
+
+	userID, ok := userIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	count, err := h.projectService.SetLike(r.Context(), projectID, userID, liked)
+	if err != nil {
+		if errors.Is(err, service.ErrProjectNotFound) {
+			utils.WriteJSONError(w, http.StatusNotFound, err.Error(), utils.ErrCodeNotFound)
+			return
+		}
+		log.Printf("Error updating like of project %d: %v", projectID, err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to update like", utils.ErrCodeInternal)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(dto.LikeResponse{Liked: liked, LikeCount: count}); err != nil {
+		log.Println("Failed to write response:", err)
+	}
+}
+
+// BatchLikes applies a list of likes and unlikes for the authenticated user and reports the
+// result of each.
+func (h *ProjectHandler) BatchLikes(w http.ResponseWriter, r *http.Request) {
+	var items []dto.LikeBatchItem
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&items); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid request body: "+err.Error(), utils.ErrCodeBadRequest)
+		return
+	}
+
+	userID, ok := userIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	results, err := h.projectService.SetLikes(r.Context(), userID, items)
+	if err != nil {
+		if errors.Is(err, service.ErrValidation) {
+			utils.WriteJSONError(w, http.StatusBadRequest, err.Error(), utils.ErrCodeValidation)
+			return
+		}
+		log.Printf("Error applying like batch for user %d: %v", userID, err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to update likes", utils.ErrCodeInternal)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(dto.LikeBatchResponse{Results: results}); err != nil {
+		log.Println("Failed to write response:", err)
+	}
+}
+
+// CreatePreviewToken issues a time-limited token for sharing the project while it is private.
+// Only the project's owner may.
+func (h *ProjectHandler) CreatePreviewToken(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid project ID", utils.ErrCodeBadRequest)
+		return
+	}
+
+	userID, ok := userIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	token, err := h.projectService.CreatePreviewToken(r.Context(), id, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrForbidden):
+			utils.WriteJSONError(w, http.StatusForbidden, err.Error(), utils.ErrCodeForbidden)
+		case errors.Is(err, service.ErrProjectNotFound):
+			utils.WriteJSONError(w, http.StatusNotFound, err.Error(), utils.ErrCodeNotFound)
+		default:
+			log.Printf("Error creating preview token for project %d: %v", id, err)
+			utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to create preview token", utils.ErrCodeInternal)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(token); err != nil {
+		log.Println("Failed to write response:", err)
+	}
+}
+
+// RevokePreviewTokens invalidates every preview token issued for the project.
+func (h *ProjectHandler) RevokePreviewTokens(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid project ID", utils.ErrCodeBadRequest)
+		return
+	}
+
+	userID, ok := userIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.projectService.RevokePreviewTokens(r.Context(), id, userID); err != nil {
+		switch {
+		case errors.Is(err, service.ErrForbidden):
+			utils.WriteJSONError(w, http.StatusForbidden, err.Error(), utils.ErrCodeForbidden)
+		case errors.Is(err, service.ErrProjectNotFound):
+			utils.WriteJSONError(w, http.StatusNotFound, err.Error(), utils.ErrCodeNotFound)
+		default:
+			log.Printf("Error revoking preview tokens of project %d: %v", id, err)
+			utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to revoke preview tokens", utils.ErrCodeInternal)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tarsuniversecentral/project-module/config"
+	"github.com/tarsuniversecentral/project-module/internal/dto"
+	"github.com/tarsuniversecentral/project-module/internal/middleware"
+	"github.com/tarsuniversecentral/project-module/internal/models"
+	service "github.com/tarsuniversecentral/project-module/internal/services"
+	"github.com/tarsuniversecentral/project-module/internal/testdb"
+	"github.com/tarsuniversecentral/project-module/pkg/storage"
+)
+
+// newTestProjectHandler wires a handler the way cmd/main.go does, over the given model
+// and a file service storing uploads in a temporary directory.
+func newTestProjectHandler(t *testing.T, model *models.ProjectModel) *ProjectHandler {
+	t.Helper()
+	cfg := &config.Config{UploadConcurrency: 2}
+	return NewProjectHandler(service.NewProjectService(model, cfg), service.NewFileService(cfg, storage.NewLocalStorage(t.TempDir())))
+}
+
+// multipartBody encodes fields and files (form field, filename, content) as a multipart
+// form and returns the body and its Content-Type.
+func multipartBody(t *testing.T, fields map[string]string, files ...[3]string) (*bytes.Buffer, string) {
+	t.Helper()
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for name, value := range fields {
+		if err := w.WriteField(name, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, f := range files {
+		part, err := w.CreateFormFile(f[0], f[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write([]byte(f[2]))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &body, w.FormDataContentType()
+}
+
+func TestCreateProjectRejectsInvalidUploads(t *testing.T) {
+	// The requests fail before anything is stored, so the model needs no database.
+	h := newTestProjectHandler(t, models.NewProjectModel(nil, nil, nil, nil, 0, 0))
+
+	tests := []struct {
+		name   string
+		fields map[string]string
+		files  [][3]string
+		want   int
+	}{
+		{"pdf with other content", map[string]string{"title": "Deck"}, [][3]string{{"pdfs", "deck.pdf", "not a pdf"}}, http.StatusUnsupportedMediaType},
+		{"wrong extension", map[string]string{"title": "Deck"}, [][3]string{{"images", "photo.exe", "MZ"}}, http.StatusUnsupportedMediaType},
+		{"missing title", nil, [][3]string{{"pdfs", "deck.pdf", "%PDF-1.4"}}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, contentType := multipartBody(t, tt.fields, tt.files...)
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/projects", body)
+			r.Header.Set("Content-Type", contentType)
+			r = r.WithContext(middleware.WithUserID(r.Context(), 7))
+			w := httptest.NewRecorder()
+
+			h.CreateProject(w, r)
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d; body: %s", w.Code, tt.want, w.Body)
+			}
+		})
+	}
+}
+
+func TestCreateProjectReturnsLocation(t *testing.T) {
+	db, tables, dialect := testdb.Open(t)
+	model := models.NewProjectModel(db, nil, tables, dialect, 0, 0)
+	t.Cleanup(func() { model.Close() })
+	h := newTestProjectHandler(t, model)
+
+	body, contentType := multipartBody(t, map[string]string{"title": "Located"}, [3]string{"pdfs", "deck.pdf", "%PDF-1.4\n"})
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/projects", body)
+	r.Header.Set("Content-Type", contentType)
+	r = r.WithContext(middleware.WithUserID(r.Context(), 7))
+	w := httptest.NewRecorder()
+
+	h.CreateProject(w, r)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d; body: %s", w.Code, http.StatusCreated, w.Body)
+	}
+	var created dto.Project
+	if err := json.NewDecoder(w.Body).Decode(&created); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if want := fmt.Sprintf("%s/projects/%d", dto.APIBasePath, created.ID); w.Header().Get("Location") != want {
+		t.Errorf("Location = %q, want %q", w.Header().Get("Location"), want)
+	}
+}
+
+func TestBatchLikesRejectsInvalidBatches(t *testing.T) {
+	// Validation fails before the model is used, so it needs no database.
+	h := newTestProjectHandler(t, models.NewProjectModel(nil, nil, nil, nil, 0, 0))
+
+	tooMany := make([]dto.LikeBatchItem, 101)
+	for i := range tooMany {
+		tooMany[i] = dto.LikeBatchItem{ProjectID: i + 1, Action: dto.LikeActionLike}
+	}
+	oversized, err := json.Marshal(tooMany)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"not an array", `{"project_id": 1, "action": "like"}`},
+		{"empty", `[]`},
+		{"unknown action", `[{"project_id": 1, "action": "love"}]`},
+		{"invalid project id", `[{"project_id": 0, "action": "like"}]`},
+		{"unknown field", `[{"project_id": 1, "action": "like", "note": "x"}]`},
+		{"over the cap", string(oversized)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/likes/batch", bytes.NewBufferString(tt.body))
+			r = r.WithContext(middleware.WithUserID(r.Context(), 7))
+			w := httptest.NewRecorder()
+
+			h.BatchLikes(w, r)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d; body: %s", w.Code, http.StatusBadRequest, w.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// alwaysRedactedHeaders are never logged regardless of configuration.
+var alwaysRedactedHeaders = []string{"Authorization", "Cookie", "Set-Cookie", "Proxy-Authorization"}
+
+// DebugBodyOptions configures the DebugBodyLogger middleware.
+type DebugBodyOptions struct {
+	MaxBytes      int
+	RedactHeaders []string
+	RedactFields  []string
+}
+
+// DebugBodyLogger logs request and response headers and bodies for debugging.
+// Bodies are captured up to MaxBytes, multipart and binary payloads are never logged,
+// and the configured headers and JSON/form fields are redacted.
+func DebugBodyLogger(opts DebugBodyOptions) func(http.Handler) http.Handler {
+	redactHeaders := make(map[string]struct{})
+	for _, h := range append(alwaysRedactedHeaders, opts.RedactHeaders...) {
+		redactHeaders[http.CanonicalHeaderKey(h)] = struct{}{}
+	}
+	redactFields := make(map[string]struct{})
+	for _, f := range opts.RedactFields {
+		redactFields[strings.ToLower(f)] = struct{}{}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			reqBody := "[empty]"
+			if r.Body != nil && r.Body != http.NoBody {
+				contentType := r.Header.Get("Content-Type")
+				if !isLoggableContentType(contentType) {
+					reqBody = "[" + mediaType(contentType) + " body omitted]"
+				} else {
+					captured, truncated, err := peekBody(r, opts.MaxBytes)
+					if err != nil {
+						reqBody = "[unreadable body]"
+					} else {
+						reqBody = renderBody(captured, truncated, contentType, redactFields)
+					}
+				}
+			}
+
+			log.Printf("[debug] --> request_id=%s %s %s headers=%v body=%s",
+				RequestIDFromContext(r.Context()), r.Method, r.URL.RequestURI(), redactHeaderValues(r.Header, redactHeaders), reqBody)
+
+			rec := &bodyRecorder{ResponseWriter: w, status: http.StatusOK, max: opts.MaxBytes}
+			next.ServeHTTP(rec, r)
+
+			respBody := "[empty]"
+			contentType := rec.Header().Get("Content-Type")
+			if rec.buf.Len() > 0 || rec.truncated {
+				if isLoggableContentType(contentType) {
+					respBody = renderBody(rec.buf.Bytes(), rec.truncated, contentType, redactFields)
+				} else {
+					respBody = "[" + mediaType(contentType) + " body omitted]"
+				}
+			}
+
+			log.Printf("[debug] <-- request_id=%s %s %s status=%d headers=%v body=%s",
+				RequestIDFromContext(r.Context()), r.Method, r.URL.RequestURI(), rec.status, redactHeaderValues(rec.Header(), redactHeaders), respBody)
+		})
+	}
+}
+
+// peekBody reads up to max bytes of the request body for logging and restores
+// the body so the handler still sees the full content.
+func peekBody(r *http.Request, max int) ([]byte, bool, error) {
+	buf, err := io.ReadAll(io.LimitReader(r.Body, int64(max)+1))
+	if err != nil {
+		return nil, false, err
+	}
+	r.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(buf), r.Body), Closer: r.Body}
+
+	if len(buf) > max {
+		return buf[:max], true, nil
+	}
+	return buf, false, nil
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// renderBody returns a loggable representation of the body with sensitive fields redacted.
+// Truncated structured bodies are not logged since they can't be parsed for redaction.
+func renderBody(body []byte, truncated bool, contentType string, fields map[string]struct{}) string {
+	switch mediaType(contentType) {
+	case "application/json":
+		if truncated {
+			return "[truncated JSON body omitted]"
+		}
+		var v interface{}
+		if err := json.Unmarshal(body, &v); err != nil {
+			return "[invalid JSON body omitted]"
+		}
+		out, err := json.Marshal(redactJSON(v, fields))
+		if err != nil {
+			return "[unencodable JSON body omitted]"
+		}
+		return string(out)
+	case "application/x-www-form-urlencoded":
+		if truncated {
+			return "[truncated form body omitted]"
+		}
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return "[invalid form body omitted]"
+		}
+		for key := range values {
+			if _, ok := fields[strings.ToLower(key)]; ok {
+				values[key] = []string{redacted}
+			}
+		}
+		return values.Encode()
+	default:
+		if truncated {
+			return string(body) + "...[truncated]"
+		}
+		return string(body)
+	}
+}
+
+// redactJSON walks a decoded JSON value and replaces the values of sensitive keys.
+func redactJSON(v interface{}, fields map[string]struct{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for key, child := range val {
+			if _, ok := fields[strings.ToLower(key)]; ok {
+				val[key] = redacted
+				continue
+			}
+			val[key] = redactJSON(child, fields)
+		}
+	case []interface{}:
+		for i, child := range val {
+			val[i] = redactJSON(child, fields)
+		}
+	}
+	return v
+}
+
+func redactHeaderValues(h http.Header, redact map[string]struct{}) http.Header {
+	out := make(http.Header, len(h))
+	for key, values := range h {
+		if _, ok := redact[http.CanonicalHeaderKey(key)]; ok {
+			out[key] = []string{redacted}
+			continue
+		}
+		out[key] = values
+	}
+	return out
+}
+
+// isLoggableContentType reports whether bodies of this type are textual.
+// Multipart uploads and binary downloads are never logged.
+func isLoggableContentType(contentType string) bool {
+	mt := mediaType(contentType)
+	return mt == "" ||
+		strings.HasPrefix(mt, "text/") ||
+		mt == "application/json" ||
+		mt == "application/x-www-form-urlencoded"
+}
+
+func mediaType(contentType string) string {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(contentType))
+	}
+	return mt
+}
+
+// bodyRecorder captures the status code and up to max bytes of the response body.
+type bodyRecorder struct {
+	http.ResponseWriter
+	status    int
+	buf       bytes.Buffer
+	max       int
+	truncated bool
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer (e.g. to flush event streams).
+func (rec *bodyRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+func (rec *bodyRecorder) WriteHeader(status int) {
+	rec.status = status
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *bodyRecorder) Write(b []byte) (int, error) {
+	if isLoggableContentType(rec.Header().Get("Content-Type")) {
+		if room := rec.max - rec.buf.Len(); room > 0 {
+			if len(b) > room {
+				rec.buf.Write(b[:room])
+				rec.truncated = true
+			} else {
+				rec.buf.Write(b)
+			}
+		} else if len(b) > 0 {
+			rec.truncated = true
+		}
+	} else if len(b) > 0 {
+		rec.truncated = true
+	}
+	return rec.ResponseWriter.Write(b)
+}
@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Deprecated marks responses of routes kept only for compatibility. It sets the Deprecation
+// header and a Link to the same path under successorPrefix, where the route now lives.
+func Deprecated(successorPrefix string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", "true")
+			w.Header().Set("Link", fmt.Sprintf(`<%s%s>; rel="successor-version"`, successorPrefix, r.URL.EscapedPath()))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
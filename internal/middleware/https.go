@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// EnforceHTTPS redirects plaintext requests to HTTPS and sets an HSTS header on secure responses.
+// It relies on the X-Forwarded-Proto header, so it must only be enabled when the service runs
+// behind a trusted proxy that sets (and overwrites) that header.
+func EnforceHTTPS(hstsMaxAge time.Duration) func(http.Handler) http.Handler {
+	hsts := fmt.Sprintf("max-age=%d", int(hstsMaxAge.Seconds()))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			proto := strings.ToLower(strings.TrimSpace(r.Header.Get("X-Forwarded-Proto")))
+
+			if r.TLS == nil && proto == "http" {
+				target := "https://" + r.Host + r.URL.RequestURI()
+				// 308 keeps the method and body, so POST/PUT clients aren't silently downgraded to GET.
+				http.Redirect(w, r, target, http.StatusPermanentRedirect)
+				return
+			}
+
+			if r.TLS != nil || proto == "https" {
+				w.Header().Set("Strict-Transport-Security", hsts)
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
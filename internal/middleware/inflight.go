@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/tarsuniversecentral/project-module/pkg/utils"
+)
+
+// InFlightLimiter caps the number of requests handled concurrently. Requests over the
+// limit are shed immediately with 503 and Retry-After instead of queueing, so a traffic
+// spike can't exhaust database connections or memory.
+type InFlightLimiter struct {
+	sem        chan struct{}
+	retryAfter time.Duration
+	exempt     map[string]struct{}
+}
+
+// NewInFlightLimiter returns a limiter allowing limit concurrent requests. Requests to
+// exemptPaths (e.g. health checks) are never counted or rejected.
+func NewInFlightLimiter(limit int, retryAfter time.Duration, exemptPaths ...string) *InFlightLimiter {
+	exempt := make(map[string]struct{}, len(exemptPaths))
+	for _, p := range exemptPaths {
+		exempt[p] = struct{}{}
+	}
+	return &InFlightLimiter{
+		sem:        make(chan struct{}, limit),
+		retryAfter: retryAfter,
+		exempt:     exempt,
+	}
+}
+
+// Middleware enforces the limit.
+func (l *InFlightLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := l.exempt[r.URL.Path]; ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		select {
+		case l.sem <- struct{}{}:
+			defer func() { <-l.sem }()
+			next.ServeHTTP(w, r)
+		default:
+			utils.WriteRetryableError(w, http.StatusServiceUnavailable, "Server is overloaded, try again later", utils.ErrCodeUnavailable, l.retryAfter)
+		}
+	})
+}
+
+// InFlight returns the number of requests currently being handled.
+func (l *InFlightLimiter) InFlight() int {
+	return len(l.sem)
+}
+
+// Limit returns the configured maximum of concurrent requests.
+func (l *InFlightLimiter) Limit() int {
+	return cap(l.sem)
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/tarsuniversecentral/project-module/pkg/utils"
+)
+
+// JWTAuth requires a valid HS256 bearer token signed with secret. The token must carry an
+// exp claim and a subject (sub) holding the positive integer user ID, which is stored in
+// the request context (see UserIDFromContext), along with the optional role claim (see
+// UserRoleFromContext). When issuer is non-empty the iss claim must match it. Requests
+// with an invalid token get 401 with a JSON error body, as do requests without one when
+// required is true; otherwise those pass through anonymously.
+func JWTAuth(secret []byte, issuer string, required bool) func(http.Handler) http.Handler {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithExpirationRequired(),
+	}
+	if issuer != "" {
+		opts = append(opts, jwt.WithIssuer(issuer))
+	}
+	parser := jwt.NewParser(opts...)
+	keyFunc := func(*jwt.Token) (interface{}, error) { return secret, nil }
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			raw, ok := bearerToken(r)
+			if !ok {
+				if required || r.Header.Get("Authorization") != "" {
+					unauthorized(w, "missing bearer token")
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			var claims authClaims
+			if _, err := parser.ParseWithClaims(raw, &claims, keyFunc); err != nil {
+				unauthorized(w, "invalid token")
+				return
+			}
+
+			userID, err := strconv.Atoi(claims.Subject)
+			if err != nil || userID <= 0 {
+				unauthorized(w, "token subject is not a valid user ID")
+				return
+			}
+
+			ctx := WithUserID(r.Context(), userID)
+			if claims.Role != "" {
+				ctx = WithUserRole(ctx, claims.Role)
+			}
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// authClaims are the registered claims plus the role the issuer granted the user.
+type authClaims struct {
+	jwt.RegisteredClaims
+	Role string `json:"role,omitempty"`
+}
+
+// bearerToken extracts the token from an "Authorization: Bearer <token>" header.
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+func unauthorized(w http.ResponseWriter, message string) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+	utils.WriteJSONError(w, http.StatusUnauthorized, message, utils.ErrCodeUnauthorized)
+}
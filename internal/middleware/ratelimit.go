@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/tarsuniversecentral/project-module/pkg/utils"
+)
+
+// RateLimiter applies a token bucket per client IP. Clients over their limit get 429
+// with Retry-After. Buckets idle for longer than the idle TTL are swept periodically
+// so one-off clients don't accumulate in memory.
+type RateLimiter struct {
+	rps        rate.Limit
+	burst      int
+	idleTTL    time.Duration
+	retryAfter time.Duration
+	exempt     map[string]struct{}
+
+	mu        sync.Mutex
+	clients   map[string]*clientLimiter
+	lastSweep time.Time
+}
+
+type clientLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// NewRateLimiter returns a limiter allowing each client rps requests per second with
+// bursts of up to burst requests. retryAfter is advertised when a request can never
+// fit the bucket. Requests to exemptPaths (e.g. health checks) are never limited.
+func NewRateLimiter(rps float64, burst int, idleTTL, retryAfter time.Duration, exemptPaths ...string) *RateLimiter {
+	exempt := make(map[string]struct{}, len(exemptPaths))
+	for _, p := range exemptPaths {
+		exempt[p] = struct{}{}
+	}
+	return &RateLimiter{
+		rps:        rate.Limit(rps),
+		burst:      burst,
+		idleTTL:    idleTTL,
+		retryAfter: retryAfter,
+		exempt:     exempt,
+		clients:    make(map[string]*clientLimiter),
+		lastSweep:  time.Now(),
+	}
+}
+
+// Middleware enforces the limit. It must run after ClientIPMiddleware so clients behind
+// a trusted proxy are keyed by the address it resolves, which they can't choose.
+func (l *RateLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := l.exempt[r.URL.Path]; ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if wait, ok := l.allow(ClientIP(r)); !ok {
+			utils.WriteRetryableError(w, http.StatusTooManyRequests, "Too many requests, slow down", utils.ErrCodeRateLimited, wait)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// allow takes a token for the client. When none is available it reports how long
+// until one will be.
+func (l *RateLimiter) allow(client string) (time.Duration, bool) {
+	now := time.Now()
+	limiter := l.limiterFor(client, now)
+
+	reservation := limiter.ReserveN(now, 1)
+	if !reservation.OK() {
+		return l.retryAfter, false
+	}
+	if wait := reservation.DelayFrom(now); wait > 0 {
+		// Don't hold the token for a request that is being rejected.
+		reservation.CancelAt(now)
+		return wait, false
+	}
+	return 0, true
+}
+
+// limiterFor returns the client's bucket, creating it if needed, and sweeps idle
+// buckets at most once per idle TTL.
+func (l *RateLimiter) limiterFor(client string, now time.Time) *rate.Limiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) >= l.idleTTL {
+		for key, c := range l.clients {
+			if now.Sub(c.lastSeen) >= l.idleTTL {
+				delete(l.clients, key)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	c, ok := l.clients[client]
+	if !ok {
+		c = &clientLimiter{limiter: rate.NewLimiter(l.rps, l.burst)}
+		l.clients[client] = c
+	}
+	c.lastSeen = now
+	return c.limiter
+}
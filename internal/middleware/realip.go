@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+)
+
+type clientIPKey struct{}
+
+// ClientIPMiddleware resolves the client's IP address once per request and stores it in the
+// request context. X-Forwarded-For is only honored when trustProxy is true, since clients
+// can set it freely when the service is reachable directly.
+//
+// Even behind a proxy the client controls the left of the header, as proxies append to
+// whatever they receive. The client is therefore the right-most address that isn't one of
+// trustedProxies (IPs or CIDR ranges). With none listed, that is the last entry: the address
+// the single proxy in front of the service saw. When trustedProxies is set, the header is
+// ignored on connections that don't come from one of them, so clients reaching the service
+// directly can't pick their own address.
+func ClientIPMiddleware(trustProxy bool, trustedProxies []string) func(http.Handler) http.Handler {
+	trusted := parseTrustedProxies(trustedProxies)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := remoteIP(r)
+			if trustProxy && (len(trusted) == 0 || isTrustedProxy(net.ParseIP(ip), trusted)) {
+				header := strings.Join(r.Header.Values("X-Forwarded-For"), ",")
+				if forwarded := forwardedClientIP(header, trusted); forwarded != "" {
+					ip = forwarded
+				}
+			}
+
+			ctx := context.WithValue(r.Context(), clientIPKey{}, ip)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// ClientIP returns the client IP resolved by ClientIPMiddleware, falling back to the
+// connection's remote address when the middleware didn't run.
+func ClientIP(r *http.Request) string {
+	if ip, ok := r.Context().Value(clientIPKey{}).(string); ok && ip != "" {
+		return ip
+	}
+	return remoteIP(r)
+}
+
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// forwardedClientIP walks an X-Forwarded-For value from the right, skipping trusted
+// proxies, and returns the first other address. It returns "" when there is none, or when
+// it reaches an entry that isn't an IP address, as nothing left of it can be relied on.
+func forwardedClientIP(header string, trusted []*net.IPNet) string {
+	if strings.TrimSpace(header) == "" {
+		return ""
+	}
+
+	parts := strings.Split(header, ",")
+	for i := len(parts) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(parts[i]))
+		if ip == nil {
+			return ""
+		}
+		if !isTrustedProxy(ip, trusted) {
+			return ip.String()
+		}
+	}
+	return ""
+}
+
+// parseTrustedProxies parses IPs and CIDR ranges, skipping invalid entries, which
+// config validation reports.
+func parseTrustedProxies(entries []string) []*net.IPNet {
+	var nets []*net.IPNet
+	for _, entry := range entries {
+		if _, ipNet, err := net.ParseCIDR(entry); err == nil {
+			nets = append(nets, ipNet)
+			continue
+		}
+		if ip := net.ParseIP(entry); ip != nil {
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 8*net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+		}
+	}
+	return nets
+}
+
+func isTrustedProxy(ip net.IP, trusted []*net.IPNet) bool {
+	for _, ipNet := range trusted {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestForwardedClientIP(t *testing.T) {
+	trusted := parseTrustedProxies([]string{"10.0.0.0/8", "192.0.2.7"})
+
+	tests := []struct {
+		name    string
+		header  string
+		trusted bool
+		want    string
+	}{
+		{"empty", "", false, ""},
+		{"single entry", "203.0.113.5", false, "203.0.113.5"},
+		{"spoofed left entry ignored", "1.2.3.4, 203.0.113.5", false, "203.0.113.5"},
+		{"trusted proxies skipped", "1.2.3.4, 203.0.113.5, 10.1.2.3, 192.0.2.7", true, "203.0.113.5"},
+		{"untrusted proxy is the client", "203.0.113.5, 198.51.100.9", true, "198.51.100.9"},
+		{"all trusted", "10.0.0.1, 192.0.2.7", true, ""},
+		{"malformed right entry", "203.0.113.5, garbage", false, ""},
+		{"ipv6", "2001:db8::1", false, "2001:db8::1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list := trusted
+			if !tt.trusted {
+				list = nil
+			}
+			if got := forwardedClientIP(tt.header, list); got != tt.want {
+				t.Errorf("forwardedClientIP(%q) = %q, want %q", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientIPMiddleware(t *testing.T) {
+	resolve := func(trustProxy bool, trustedProxies []string, remoteAddr string, forwarded ...string) string {
+		var got string
+		handler := ClientIPMiddleware(trustProxy, trustedProxies)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = ClientIP(r)
+		}))
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = remoteAddr
+		for _, v := range forwarded {
+			r.Header.Add("X-Forwarded-For", v)
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), r)
+		return got
+	}
+
+	if got := resolve(false, nil, "198.51.100.1:4000", "203.0.113.5"); got != "198.51.100.1" {
+		t.Errorf("untrusted header: got %q, want the remote address", got)
+	}
+	if got := resolve(true, nil, "10.0.0.1:4000", "1.2.3.4", "203.0.113.5"); got != "203.0.113.5" {
+		t.Errorf("repeated headers: got %q, want the right-most entry", got)
+	}
+	if got := resolve(true, nil, "10.0.0.1:4000"); got != "10.0.0.1" {
+		t.Errorf("no header: got %q, want the remote address", got)
+	}
+	if got := resolve(true, []string{"10.0.0.0/8"}, "10.0.0.1:4000", "203.0.113.5"); got != "203.0.113.5" {
+		t.Errorf("trusted peer: got %q, want the forwarded address", got)
+	}
+	if got := resolve(true, []string{"10.0.0.0/8"}, "198.51.100.1:4000", "203.0.113.5"); got != "198.51.100.1" {
+		t.Errorf("direct client: got %q, want the remote address", got)
+	}
+}
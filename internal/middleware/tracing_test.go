@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/tarsuniversecentral/project-module/pkg/tracing"
+)
+
+type testSpan struct {
+	name  string
+	attrs map[string]any
+	err   error
+	ended bool
+}
+
+func (s *testSpan) SetAttribute(key string, value any) { s.attrs[key] = value }
+func (s *testSpan) RecordError(err error) {
+	if err != nil {
+		s.err = err
+	}
+}
+func (s *testSpan) End() { s.ended = true }
+
+type testTracer struct{ spans []*testSpan }
+
+func (t *testTracer) Start(ctx context.Context, name string) (context.Context, tracing.Span) {
+	span := &testSpan{name: name, attrs: map[string]any{}}
+	t.spans = append(t.spans, span)
+	return ctx, span
+}
+
+func TestTracing(t *testing.T) {
+	tracer := &testTracer{}
+	tracing.SetTracer(tracer)
+	t.Cleanup(func() { tracing.SetTracer(nil) })
+
+	router := mux.NewRouter()
+	router.Use(Tracing)
+	router.HandleFunc("/projects/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/projects/42", nil))
+
+	if len(tracer.spans) != 1 {
+		t.Fatalf("started %d spans, want 1", len(tracer.spans))
+	}
+	span := tracer.spans[0]
+	if span.name != "GET /projects/{id}" {
+		t.Errorf("span name = %q, want %q", span.name, "GET /projects/{id}")
+	}
+	if span.attrs["http.status_code"] != http.StatusInternalServerError {
+		t.Errorf("http.status_code = %v, want %d", span.attrs["http.status_code"], http.StatusInternalServerError)
+	}
+	if span.err == nil {
+		t.Error("server error not recorded on the span")
+	}
+	if !span.ended {
+		t.Error("span not ended")
+	}
+}
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/tarsuniversecentral/project-module/pkg/utils"
+)
+
+type (
+	userIDKey   struct{}
+	userRoleKey struct{}
+)
+
+// RoleAdmin is the role that may use the /admin routes.
+const RoleAdmin = "admin"
+
+// UserContextMiddleware reads the interim X-User-ID header, validates that it is a positive
+// integer and stores it in the request context, along with the X-User-Role header if set.
+// When required is true, requests without a valid user ID are rejected with 401; otherwise
+// they pass through anonymously.
+func UserContextMiddleware(required bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			raw := r.Header.Get("X-User-ID")
+			if raw == "" {
+				if required {
+					utils.WriteJSONError(w, http.StatusUnauthorized, "Missing X-User-ID header", utils.ErrCodeUnauthorized)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			userID, err := strconv.Atoi(raw)
+			if err != nil || userID <= 0 {
+				utils.WriteJSONError(w, http.StatusUnauthorized, "Invalid X-User-ID header", utils.ErrCodeUnauthorized)
+				return
+			}
+
+			ctx := WithUserID(r.Context(), userID)
+			if role := r.Header.Get("X-User-Role"); role != "" {
+				ctx = WithUserRole(ctx, role)
+			}
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequireRole rejects requests whose user doesn't hold role with 403. It must run after the
+// middleware that authenticates the user.
+func RequireRole(role string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if got, _ := UserRoleFromContext(r.Context()); got != role {
+				utils.WriteJSONError(w, http.StatusForbidden, "The "+role+" role is required", utils.ErrCodeForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// WithUserID returns a copy of ctx carrying the authenticated user's ID.
+func WithUserID(ctx context.Context, userID int) context.Context {
+	return context.WithValue(ctx, userIDKey{}, userID)
+}
+
+// UserIDFromContext returns the authenticated user's ID, if any.
+func UserIDFromContext(ctx context.Context) (int, bool) {
+	userID, ok := ctx.Value(userIDKey{}).(int)
+	return userID, ok
+}
+
+// WithUserRole returns a copy of ctx carrying the authenticated user's role.
+func WithUserRole(ctx context.Context, role string) context.Context {
+	return context.WithValue(ctx, userRoleKey{}, role)
+}
+
+// UserRoleFromContext returns the authenticated user's role, if any.
+func UserRoleFromContext(ctx context.Context) (string, bool) {
+	role, ok := ctx.Value(userRoleKey{}).(string)
+	return role, ok
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestRequireRole(t *testing.T) {
+	secret := []byte("test-secret")
+	token := func(role string) string {
+		claims := authClaims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Subject:   "42",
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			},
+			Role: role,
+		}
+		signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return "Bearer " + signed
+	}
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	jwtAdmin := JWTAuth(secret, "", true)(RequireRole(RoleAdmin)(ok))
+	headerAdmin := UserContextMiddleware(true)(RequireRole(RoleAdmin)(ok))
+
+	tests := []struct {
+		name    string
+		handler http.Handler
+		headers map[string]string
+		want    int
+	}{
+		{"admin token", jwtAdmin, map[string]string{"Authorization": token(RoleAdmin)}, http.StatusOK},
+		{"token without role", jwtAdmin, map[string]string{"Authorization": token("")}, http.StatusForbidden},
+		{"token with other role", jwtAdmin, map[string]string{"Authorization": token("member")}, http.StatusForbidden},
+		{"role header ignored with tokens", jwtAdmin, map[string]string{"Authorization": token(""), "X-User-Role": RoleAdmin}, http.StatusForbidden},
+		{"no token", jwtAdmin, nil, http.StatusUnauthorized},
+		{"admin header", headerAdmin, map[string]string{"X-User-ID": "42", "X-User-Role": RoleAdmin}, http.StatusOK},
+		{"no role header", headerAdmin, map[string]string{"X-User-ID": "42"}, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/admin", nil)
+			for k, v := range tt.headers {
+				r.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			tt.handler.ServeHTTP(w, r)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,17 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/tarsuniversecentral/project-module/internal/testdb"
+)
+
+// openTestModel returns a model over freshly migrated tables in the test database, or
+// skips the test when there is none (see testdb.Open).
+func openTestModel(tb testing.TB) *ProjectModel {
+	tb.Helper()
+	db, tables, dialect := testdb.Open(tb)
+	m := NewProjectModel(db, nil, tables, dialect, 0, 0)
+	tb.Cleanup(func() { m.Close() })
+	return m
+}
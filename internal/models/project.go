@@ -1,29 +1,120 @@
 package models
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 	"log"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/tarsuniversecentral/project-module/internal/dto"
+	"github.com/tarsuniversecentral/project-module/pkg/database"
 )
 
+// ErrVersionMismatch is returned by an update made against a version of the row that is no
+// longer current, because someone else updated it first.
+var ErrVersionMismatch = errors.New("version mismatch")
+
+// ProjectModel sends writes to the primary database and routes read-only
+// queries through the replica set, which falls back to the primary.
+//
+// Every method has a ...Context variant that stops its queries when the context is done,
+// e.g. when the client disconnects or the server shuts down. Each call is also bounded by
+// the query timeout. The variants without a context use context.Background.
 type ProjectModel struct {
-	db *sql.DB
+	db           *sql.DB
+	replicas     *database.ReplicaSet
+	stmts        *stmtCache
+	tables       *database.TableNamer
+	dialect      *database.Dialect
+	queryTimeout time.Duration
+	slowQueries  *slowQueryLogger
+}
+
+// NewProjectModel returns a model whose calls each give up after queryTimeout and which logs
+// statements slower than slowQueryThreshold. Zero disables the timeout or the log.
+func NewProjectModel(db *sql.DB, replicas *database.ReplicaSet, tables *database.TableNamer, dialect *database.Dialect, queryTimeout, slowQueryThreshold time.Duration) *ProjectModel {
+	return &ProjectModel{
+		db:           db,
+		replicas:     replicas,
+		stmts:        newStmtCache(),
+		tables:       tables,
+		dialect:      dialect,
+		queryTimeout: queryTimeout,
+		slowQueries:  newSlowQueryLogger(slowQueryThreshold),
+	}
+}
+
+// withTimeout bounds ctx by the model's query timeout.
+func (m *ProjectModel) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if m.queryTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, m.queryTimeout)
+}
+
+// q adapts query to this deployment: it names the tables and numbers the placeholders for
+// the driver. Every statement must pass through it; SQL in this file names tables without
+// the configured prefix and uses ? placeholders.
+func (m *ProjectModel) q(query string) string {
+	return m.dialect.Rebind(m.tables.Rewrite(query))
+}
+
+// Close releases the model's prepared statements. Call it before closing the database.
+func (m *ProjectModel) Close() error {
+	return m.stmts.Close()
 }
 
-func NewProjectModel(db *sql.DB) *ProjectModel {
-	return &ProjectModel{db: db}
+// Hot-path queries, prepared once per connection pool and reused.
+const (
+	getProjectByIDQuery = `
+		SELECT id, title, subtitle, industry, description, project_value, looking_for, created_at, updated_at
+		FROM projects
+		WHERE id = ?`
+
+	projectExistsQuery = `SELECT EXISTS(SELECT 1 FROM projects WHERE id = ?)`
+
+	getTeamMembersQuery = `
+		SELECT 
+			id, 
+			project_id, 
+			profile_url, 
+			title, 
+			role,
+			is_lead,
+			version
+		FROM team_members
+		WHERE project_id = ?
+		ORDER BY id
+		LIMIT ? OFFSET ?`
+)
+
+// reader returns the connection pool for read-only queries.
+// Reads that must observe the caller's own writes should use m.db instead.
+func (m *ProjectModel) reader() *sql.DB {
+	if m.replicas == nil {
+		return m.db
+	}
+	return m.replicas.Reader()
 }
 
 // CreateProjectTx wraps the entire project creation process in a transaction.
 // It inserts the main project record and, if provided, inserts the associated
 // pitch deck and image file paths into their respective tables.
 func (m *ProjectModel) CreateProjectTx(p *dto.Project, lookingForStr string) error {
+	return m.CreateProjectTxContext(context.Background(), p, lookingForStr)
+}
+
+// CreateProjectTxContext is CreateProjectTx bounded by ctx and the model's query timeout.
+func (m *ProjectModel) CreateProjectTxContext(ctx context.Context, p *dto.Project, lookingForStr string) error {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
 	// Begin the transaction.
-	tx, err := m.db.Begin()
+	tx, err := m.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
@@ -35,20 +126,29 @@ func (m *ProjectModel) CreateProjectTx(p *dto.Project, lookingForStr string) err
 		}
 	}
 
-	// Insert the main project record.
+	// Insert the main project record. The timestamps are set here rather than left to the
+	// column defaults so the returned project carries them. TIMESTAMP stores whole seconds.
+	p.CreatedAt = dto.NewTime(time.Now().Truncate(time.Second))
+	p.UpdatedAt = p.CreatedAt
+
 	projectQuery := `
-		INSERT INTO projects (title, subtitle, industry, description, project_value, looking_for, github_link)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO projects (title, subtitle, industry, description, project_value, currency, looking_for, github_link, visibility, owner_id, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
-	result, err := tx.Exec(projectQuery,
+	lastInsertID, err := m.insertReturningID(ctx, tx, projectQuery,
 		p.Title,
-		p.Subtitle,
-		p.Industry,
-		p.Description,
+		nullIfEmpty(p.Subtitle),
+		nullIfEmpty(p.Industry),
+		nullIfEmpty(p.Description),
 		p.ProjectValue,
-		lookingForStr,
-		p.GithubLink,
+		p.Currency,
+		nullIfEmpty(lookingForStr),
+		nullIfEmpty(p.GithubLink),
+		p.Visibility,
+		sql.NullInt64{Int64: int64(p.OwnerID), Valid: p.OwnerID > 0},
+		p.CreatedAt,
+		p.UpdatedAt,
 	)
 	if err != nil {
 		rollback(tx)
@@ -56,18 +156,11 @@ func (m *ProjectModel) CreateProjectTx(p *dto.Project, lookingForStr string) err
 		return err
 	}
 
-	lastInsertID, err := result.LastInsertId()
-	if err != nil {
-		rollback(tx)
-		log.Println("Error getting last insert ID:", err)
-		return err
-	}
-
 	p.ID = int(lastInsertID)
 
 	// Insert pitch deck file paths if provided.
 	if len(p.PitchDecks) > 0 {
-		if err = m.insertProjectPitchDecksTx(tx, p.ID, p.PitchDecks); err != nil {
+		if err = m.insertProjectPitchDecksTx(ctx, tx, p.ID, p.PitchDecks, p.PitchDeckPreviews, p.OriginalNames); err != nil {
 			rollback(tx)
 			return err
 		}
@@ -75,12 +168,18 @@ func (m *ProjectModel) CreateProjectTx(p *dto.Project, lookingForStr string) err
 
 	// Insert image file paths if provided.
 	if len(p.Images) > 0 {
-		if err = m.insertProjectImagesTx(tx, p.ID, p.Images); err != nil {
+		if err = m.insertProjectImagesTx(ctx, tx, p.ID, p.Images, p.OriginalNames); err != nil {
 			rollback(tx)
 			return err
 		}
 	}
 
+	// Insert industries if provided.
+	if err = m.replaceProjectIndustriesTx(ctx, tx, p.ID, p.Industries); err != nil {
+		rollback(tx)
+		return err
+	}
+
 	// Commit the transaction.
 	if err = tx.Commit(); err != nil {
 		log.Println("Error committing transaction:", err)
@@ -90,99 +189,578 @@ func (m *ProjectModel) CreateProjectTx(p *dto.Project, lookingForStr string) err
 	return nil
 }
 
-func (m *ProjectModel) insertProjectPitchDecksTx(tx *sql.Tx, projectID int, paths []string) error {
+// insertReturningID runs an INSERT of a single row and returns the generated id. Postgres
+// drivers don't report LastInsertId, so there the id is read back with RETURNING.
+func (m *ProjectModel) insertReturningID(ctx context.Context, db querier, query string, args ...interface{}) (int64, error) {
+	if m.dialect.IsPostgres() {
+		var id int64
+		err := m.queryRow(ctx, db, m.q(query+" RETURNING id"), args...).Scan(&id)
+		return id, err
+	}
+
+	result, err := m.exec(ctx, db, m.q(query), args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// insertProjectPitchDecksTx inserts the pitch decks along with the preview image of each
+// deck that has one in previews and the name each was uploaded under in originalNames.
+func (m *ProjectModel) insertProjectPitchDecksTx(ctx context.Context, tx *sql.Tx, projectID int, paths []string, previews, originalNames map[string]string) error {
 	// Return early if there are no paths to insert.
 	if len(paths) == 0 {
 		return nil
 	}
 
 	// Build the INSERT query dynamically.
-	// For each file, we need a placeholder group "(?, ?)".
-	query := "INSERT INTO project_pitch_decks (project_id, file_path) VALUES "
+	// For each file, we need a placeholder group "(?, ?, ?, ?)".
+	query := "INSERT INTO project_pitch_decks (project_id, file_path, preview_path, original_name) VALUES "
 	placeholders := make([]string, 0, len(paths))
-	values := make([]interface{}, 0, len(paths)*2)
+	values := make([]interface{}, 0, len(paths)*4)
 
 	for _, path := range paths {
-		placeholders = append(placeholders, "(?, ?)")
-		values = append(values, projectID, path)
+		preview, ok := previews[path]
+		placeholders = append(placeholders, "(?, ?, ?, ?)")
+		values = append(values, projectID, path, sql.NullString{String: preview, Valid: ok}, nullIfEmpty(originalNames[path]))
 	}
 	query += strings.Join(placeholders, ",")
 
 	// Execute the batch insert.
-	if _, err := tx.Exec(query, values...); err != nil {
+	if _, err := m.exec(ctx, tx, m.q(query), values...); err != nil {
 		log.Println("Error batch inserting pitch decks:", err)
 		return err
 	}
 	return nil
 }
 
-func (m *ProjectModel) insertProjectImagesTx(tx *sql.Tx, projectID int, paths []string) error {
+func (m *ProjectModel) insertProjectImagesTx(ctx context.Context, tx *sql.Tx, projectID int, paths []string, originalNames map[string]string) error {
 	// Return early if there are no paths to insert.
 	if len(paths) == 0 {
 		return nil
 	}
 
 	// Build the INSERT query dynamically.
-	query := "INSERT INTO project_images (project_id, file_path) VALUES "
+	query := "INSERT INTO project_images (project_id, file_path, original_name) VALUES "
 	placeholders := make([]string, 0, len(paths))
-	values := make([]interface{}, 0, len(paths)*2)
+	values := make([]interface{}, 0, len(paths)*3)
 
 	for _, path := range paths {
-		placeholders = append(placeholders, "(?, ?)")
-		values = append(values, projectID, path)
+		placeholders = append(placeholders, "(?, ?, ?)")
+		values = append(values, projectID, path, nullIfEmpty(originalNames[path]))
 	}
 	query += strings.Join(placeholders, ",")
 
 	// Execute the batch insert.
-	if _, err := tx.Exec(query, values...); err != nil {
+	if _, err := m.exec(ctx, tx, m.q(query), values...); err != nil {
 		log.Println("Error batch inserting images:", err)
 		return err
 	}
 	return nil
 }
 
-func (m *ProjectModel) GetProjects() ([]dto.Project, error) {
-	rows, err := m.db.Query(`SELECT id, title, subtitle, industry, description, project_value, looking_for FROM projects`)
+// replaceProjectIndustriesTx replaces the project's industries with the given list.
+func (m *ProjectModel) replaceProjectIndustriesTx(ctx context.Context, tx *sql.Tx, projectID int, industries []string) error {
+	if _, err := m.exec(ctx, tx, m.q(`DELETE FROM project_industries WHERE project_id = ?`), projectID); err != nil {
+		log.Println("Error clearing industries:", err)
+		return err
+	}
+
+	if len(industries) == 0 {
+		return nil
+	}
+
+	query := "INSERT INTO project_industries (project_id, industry) VALUES "
+	placeholders := make([]string, 0, len(industries))
+	values := make([]interface{}, 0, len(industries)*2)
+
+	for _, industry := range industries {
+		placeholders = append(placeholders, "(?, ?)")
+		values = append(values, projectID, industry)
+	}
+	query += strings.Join(placeholders, ",")
+
+	if _, err := m.exec(ctx, tx, m.q(query), values...); err != nil {
+		log.Println("Error batch inserting industries:", err)
+		return err
+	}
+	return nil
+}
+
+// projectSortOrders maps the public sort keys to ORDER BY clauses. The id tiebreaker
+// keeps the ordering deterministic across pages.
+var projectSortOrders = map[string]string{
+	"created_at_desc":    "created_at DESC, id DESC",
+	"created_at_asc":     "created_at ASC, id ASC",
+	"project_value_desc": "project_value DESC, id DESC",
+	"project_value_asc":  "project_value ASC, id ASC",
+	"title_asc":          "title ASC, id ASC",
+	"title_desc":         "title DESC, id DESC",
+}
+
+// DefaultProjectSort is used when the client doesn't ask for a sort order.
+const DefaultProjectSort = "created_at_desc"
+
+// IsValidProjectSort reports whether key is a supported sort key.
+func IsValidProjectSort(key string) bool {
+	_, ok := projectSortOrders[key]
+	return ok
+}
+
+// projectListColumns are the columns selected for project summaries in list views.
+const projectListColumns = `id, title, subtitle, industry, description, project_value, currency, looking_for,
+	github_link, like_count, comment_count, view_count, verified, visibility, owner_id, created_at, updated_at`
+
+// scanProjectSummary scans a row selected with projectListColumns.
+func scanProjectSummary(rows *sql.Rows) (dto.Project, error) {
+	var (
+		p            dto.Project
+		subtitle     sql.NullString
+		industry     sql.NullString
+		description  sql.NullString
+		projectValue sql.NullFloat64
+		lookingFor   sql.NullString
+		githubLink   sql.NullString
+		likeCount    sql.NullInt64
+		commentCount sql.NullInt64
+		viewCount    sql.NullInt64
+		verified     sql.NullBool
+		ownerID      sql.NullInt64
+	)
+
+	err := rows.Scan(
+		&p.ID, &p.Title, &subtitle, &industry, &description, &projectValue, &p.Currency, &lookingFor,
+		&githubLink, &likeCount, &commentCount, &viewCount, &verified, &p.Visibility,
+		&ownerID, &p.CreatedAt, &p.UpdatedAt,
+	)
 	if err != nil {
-		return nil, err
+		return dto.Project{}, err
+	}
+
+	p.Subtitle = subtitle.String
+	p.Industry = industry.String
+	p.Description = description.String
+	p.ProjectValue = projectValue.Float64
+	p.LookingFor = parseLookingFor(lookingFor.String)
+	p.GithubLink = githubLink.String
+	p.LikeCount = int(likeCount.Int64)
+	p.CommentCount = int(commentCount.Int64)
+	p.ViewCount = int(viewCount.Int64)
+	p.Verified = verified.Bool
+	p.OwnerID = int(ownerID.Int64)
+	return p, nil
+}
+
+// buildProjectFilter turns the filter into a parameterized WHERE clause (including the
+// WHERE keyword) and its arguments.
+//
+// looking_for is stored as a comma-separated list without spaces, so each requested value
+// is matched as a whole list element: with FIND_IN_SET on MySQL and against the split list
+// on Postgres. Unlike LIKE '%Invest%', "Invest" therefore never matches "Investment".
+// Multiple values must all be present.
+//
+// Only public projects are listed; unlisted and private ones are reachable by ID only.
+// Listing one owner's projects is the exception: owners see all of their own projects.
+func buildProjectFilter(dialect *database.Dialect, filter dto.ProjectFilter) (string, []interface{}) {
+	conditions := []string{"visibility = ?"}
+	args := []interface{}{dto.VisibilityPublic}
+	if filter.OwnerID > 0 {
+		conditions = []string{"owner_id = ?"}
+		args = []interface{}{filter.OwnerID}
+	}
+
+	if len(filter.Industries) > 0 {
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(filter.Industries)), ", ")
+		conditions = append(conditions, `EXISTS (SELECT 1 FROM project_industries pi
+			WHERE pi.project_id = projects.id AND pi.industry IN (`+placeholders+`))`)
+		for _, industry := range filter.Industries {
+			args = append(args, industry)
+		}
+	}
+
+	lookingFor := "FIND_IN_SET(?, looking_for) > 0"
+	if dialect.IsPostgres() {
+		lookingFor = "? = ANY(string_to_array(looking_for, ','))"
+	}
+	for _, lf := range filter.LookingFor {
+		conditions = append(conditions, lookingFor)
+		args = append(args, lf)
+	}
+
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// FilterProjects returns one page of projects matching the filter, ordered by the given sort key.
+// Each project is returned with its team members, pitch decks and images, loaded in batch.
+func (m *ProjectModel) FilterProjects(filter dto.ProjectFilter, offset, limit int, sortColumn string) ([]dto.Project, error) {
+	return m.FilterProjectsContext(context.Background(), filter, offset, limit, sortColumn)
+}
+
+// FilterProjectsContext is FilterProjects bounded by ctx and the model's query timeout.
+func (m *ProjectModel) FilterProjectsContext(ctx context.Context, filter dto.ProjectFilter, offset, limit int, sortColumn string) ([]dto.Project, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	orderBy, ok := projectSortOrders[sortColumn]
+	if !ok {
+		return nil, fmt.Errorf("unsupported sort %q", sortColumn)
+	}
+
+	where, args := buildProjectFilter(m.dialect, filter)
+	query := fmt.Sprintf(`SELECT id FROM projects%s ORDER BY %s LIMIT ? OFFSET ?`, where, orderBy)
+	args = append(args, limit, offset)
+
+	rows, err := m.query(ctx, m.reader(), m.q(query), args...)
+	if err != nil {
+		log.Println("Error querying projects:", err)
+		return nil, fmt.Errorf("failed to query projects: %w", err)
 	}
 	defer rows.Close()
 
-	var projects []dto.Project
+	var ids []int
 	for rows.Next() {
-		var p dto.Project
-		if err := rows.Scan(&p.ID, &p.Title, &p.Subtitle, &p.Industry, &p.Description, &p.ProjectValue, &p.LookingFor); err != nil {
-			return nil, err
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan project id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return m.GetProjectsFullDetailsBatchContext(ctx, ids)
+}
+
+// GetProjectsByOwner returns one page of the user's projects, of any visibility, ordered by
+// the given sort key. Projects without an owner are never included.
+func (m *ProjectModel) GetProjectsByOwner(ownerID, offset, limit int, sortColumn string) ([]dto.Project, error) {
+	return m.GetProjectsByOwnerContext(context.Background(), ownerID, offset, limit, sortColumn)
+}
+
+// GetProjectsByOwnerContext is GetProjectsByOwner bounded by ctx and the model's query timeout.
+func (m *ProjectModel) GetProjectsByOwnerContext(ctx context.Context, ownerID, offset, limit int, sortColumn string) ([]dto.Project, error) {
+	if ownerID <= 0 {
+		return []dto.Project{}, nil
+	}
+	return m.FilterProjectsContext(ctx, dto.ProjectFilter{OwnerID: ownerID}, offset, limit, sortColumn)
+}
+
+// GetProjectsFullDetailsBatch loads the given projects with their team members, pitch decks
+// and images using one IN query per table, instead of one round of queries per project.
+// Projects are returned in the order of ids; ids that don't exist are skipped.
+func (m *ProjectModel) GetProjectsFullDetailsBatch(ids []int) ([]dto.Project, error) {
+	return m.GetProjectsFullDetailsBatchContext(context.Background(), ids)
+}
+
+// GetProjectsFullDetailsBatchContext is GetProjectsFullDetailsBatch bounded by ctx and the model's query timeout.
+func (m *ProjectModel) GetProjectsFullDetailsBatchContext(ctx context.Context, ids []int) ([]dto.Project, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	if len(ids) == 0 {
+		return []dto.Project{}, nil
+	}
+
+	db := m.reader()
+	placeholders, args := inClause(ids)
+
+	rows, err := m.query(ctx, db, m.q(fmt.Sprintf(`SELECT %s FROM projects WHERE id IN (%s)`, projectListColumns, placeholders)), args...)
+	if err != nil {
+		return nil, fmt.Errorf("query projects error: %w", err)
+	}
+	defer rows.Close()
+
+	byID := make(map[int]*dto.Project, len(ids))
+	for rows.Next() {
+		p, err := scanProjectSummary(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan project error: %w", err)
+		}
+		p.TeamMembers = []dto.TeamMember{}
+		byID[p.ID] = &p
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	// Team members.
+	memberRows, err := m.query(ctx, db, m.q(fmt.Sprintf(`
+		SELECT id, project_id, profile_url, title, role, is_lead, version
+		FROM team_members
+		WHERE project_id IN (%s)
+		ORDER BY id`, placeholders)), args...)
+	if err != nil {
+		return nil, fmt.Errorf("query team members error: %w", err)
+	}
+	defer memberRows.Close()
+
+	for memberRows.Next() {
+		var (
+			member     dto.TeamMember
+			profileURL sql.NullString
+			title      sql.NullString
+			role       sql.NullString
+		)
+		if err := memberRows.Scan(&member.ID, &member.ProjectID, &profileURL, &title, &role, &member.IsLead, &member.Version); err != nil {
+			return nil, fmt.Errorf("scan team member error: %w", err)
+		}
+		member.ProfileURL = profileURL.String
+		member.Title = title.String
+		member.Role = role.String
+
+		if p, ok := byID[member.ProjectID]; ok {
+			p.TeamMembers = append(p.TeamMembers, member)
+		}
+	}
+	if err := memberRows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	// Pitch decks, images and industries.
+	pitchDecks, err := m.queryProjectValuesBatch(ctx, db, "project_pitch_decks", "file_path", placeholders, args)
+	if err != nil {
+		return nil, err
+	}
+	previews, err := m.queryPitchDeckPreviewsBatch(ctx, db, placeholders, args)
+	if err != nil {
+		return nil, err
+	}
+	images, err := m.queryProjectValuesBatch(ctx, db, "project_images", "file_path", placeholders, args)
+	if err != nil {
+		return nil, err
+	}
+	primaryImages, err := m.queryPrimaryImagesBatch(ctx, db, placeholders, args)
+	if err != nil {
+		return nil, err
+	}
+	industries, err := m.queryProjectValuesBatch(ctx, db, "project_industries", "industry", placeholders, args)
+	if err != nil {
+		return nil, err
+	}
+
+	projects := make([]dto.Project, 0, len(byID))
+	for _, id := range ids {
+		p, ok := byID[id]
+		if !ok {
+			continue
 		}
-		projects = append(projects, p)
+		p.PitchDecks = pitchDecks[id]
+		p.PitchDeckPreviews = previews[id]
+		p.Images = images[id]
+		p.CoverImage = coverImage(images[id], primaryImages[id])
+		p.Industries = industries[id]
+		setProjectLead(p)
+		projects = append(projects, *p)
 	}
+
 	return projects, nil
 }
 
+// queryProjectValuesBatch returns the values of column in a per-project child table for
+// the given projects, keyed by project ID.
+func (m *ProjectModel) queryProjectValuesBatch(ctx context.Context, db *sql.DB, table, column, placeholders string, args []interface{}) (map[int][]string, error) {
+	rows, err := m.query(ctx, db, m.q(fmt.Sprintf(`SELECT project_id, %s FROM %s WHERE project_id IN (%s) ORDER BY id`, column, table, placeholders)), args...)
+	if err != nil {
+		return nil, fmt.Errorf("query %s error: %w", table, err)
+	}
+	defer rows.Close()
+
+	values := make(map[int][]string)
+	for rows.Next() {
+		var (
+			projectID int
+			value     string
+		)
+		if err := rows.Scan(&projectID, &value); err != nil {
+			return nil, fmt.Errorf("scan %s error: %w", table, err)
+		}
+		values[projectID] = append(values[projectID], value)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return values, nil
+}
+
+// queryPrimaryImagesBatch returns the primary image of each project that has one, keyed by
+// project ID.
+func (m *ProjectModel) queryPrimaryImagesBatch(ctx context.Context, db *sql.DB, placeholders string, args []interface{}) (map[int]string, error) {
+	rows, err := m.query(ctx, db, m.q(fmt.Sprintf(`
+		SELECT project_id, file_path
+		FROM project_images
+		WHERE project_id IN (%s) AND is_primary = TRUE`, placeholders)), args...)
+	if err != nil {
+		return nil, fmt.Errorf("query primary images error: %w", err)
+	}
+	defer rows.Close()
+
+	primary := make(map[int]string)
+	for rows.Next() {
+		var (
+			projectID int
+			filePath  string
+		)
+		if err := rows.Scan(&projectID, &filePath); err != nil {
+			return nil, fmt.Errorf("scan primary image error: %w", err)
+		}
+		primary[projectID] = filePath
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return primary, nil
+}
+
+// queryPitchDeckPreviewsBatch returns the preview image of each pitch deck that has one,
+// keyed by project ID and then by pitch deck file path.
+func (m *ProjectModel) queryPitchDeckPreviewsBatch(ctx context.Context, db *sql.DB, placeholders string, args []interface{}) (map[int]map[string]string, error) {
+	rows, err := m.query(ctx, db, m.q(fmt.Sprintf(`
+		SELECT project_id, file_path, preview_path
+		FROM project_pitch_decks
+		WHERE project_id IN (%s) AND preview_path IS NOT NULL`, placeholders)), args...)
+	if err != nil {
+		return nil, fmt.Errorf("query pitch deck previews error: %w", err)
+	}
+	defer rows.Close()
+
+	previews := make(map[int]map[string]string)
+	for rows.Next() {
+		var (
+			projectID     int
+			deck, preview string
+		)
+		if err := rows.Scan(&projectID, &deck, &preview); err != nil {
+			return nil, fmt.Errorf("scan pitch deck preview error: %w", err)
+		}
+		if previews[projectID] == nil {
+			previews[projectID] = make(map[string]string)
+		}
+		previews[projectID][deck] = preview
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return previews, nil
+}
+
+// inClause returns "?, ?, ..." for the values along with the matching query arguments.
+func inClause[T any](values []T) (string, []interface{}) {
+	placeholders := make([]string, len(values))
+	args := make([]interface{}, len(values))
+	for i, v := range values {
+		placeholders[i] = "?"
+		args[i] = v
+	}
+	return strings.Join(placeholders, ", "), args
+}
+
+// coverImage picks the project's thumbnail: the image marked primary, or else the first
+// one uploaded.
+func coverImage(images []string, primary string) string {
+	if primary != "" {
+		return primary
+	}
+	if len(images) > 0 {
+		return images[0]
+	}
+	return ""
+}
+
+// setProjectLead surfaces the lead separately so clients don't have to search the team list.
+func setProjectLead(p *dto.Project) {
+	for i := range p.TeamMembers {
+		if p.TeamMembers[i].IsLead {
+			lead := p.TeamMembers[i]
+			p.Lead = &lead
+			return
+		}
+	}
+}
+
+// CountProjects returns the number of projects matching the filter, using the same
+// WHERE clause as FilterProjects so totals line up with the listed pages.
+func (m *ProjectModel) CountProjects(filter dto.ProjectFilter) (int, error) {
+	return m.CountProjectsContext(context.Background(), filter)
+}
+
+// CountProjectsContext is CountProjects bounded by ctx and the model's query timeout.
+func (m *ProjectModel) CountProjectsContext(ctx context.Context, filter dto.ProjectFilter) (int, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	count, _, err := m.ProjectListStatsContext(ctx, filter)
+	return count, err
+}
+
+// ProjectListStats returns the number of projects matching the filter and the newest
+// updated_at among them (zero if there are none).
+func (m *ProjectModel) ProjectListStats(filter dto.ProjectFilter) (int, time.Time, error) {
+	return m.ProjectListStatsContext(context.Background(), filter)
+}
+
+// ProjectListStatsContext is ProjectListStats bounded by ctx and the model's query timeout.
+func (m *ProjectModel) ProjectListStatsContext(ctx context.Context, filter dto.ProjectFilter) (int, time.Time, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	where, args := buildProjectFilter(m.dialect, filter)
+
+	var (
+		count        int
+		lastModified sql.NullTime
+	)
+	query := `SELECT COUNT(*), MAX(updated_at) FROM projects` + where
+	if err := m.queryRow(ctx, m.reader(), m.q(query), args...).Scan(&count, &lastModified); err != nil {
+		log.Println("Error counting projects:", err)
+		return 0, time.Time{}, fmt.Errorf("failed to count projects: %w", err)
+	}
+	return count, lastModified.Time, nil
+}
+
+// GetProjectByID returns the project with the given ID, or sql.ErrNoRows if there is none.
 func (m *ProjectModel) GetProjectByID(id int) (*dto.Project, error) {
+	return m.GetProjectByIDContext(context.Background(), id)
+}
+
+// GetProjectByIDContext is GetProjectByID bounded by ctx and the model's query timeout.
+func (m *ProjectModel) GetProjectByIDContext(ctx context.Context, id int) (*dto.Project, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
 	var p dto.Project
 
+	stmt, err := m.stmts.get(m.reader(), m.q(getProjectByIDQuery))
+	if err != nil {
+		return nil, err
+	}
+
 	// Query to select the project by its ID
-	row := m.db.QueryRow(`
-		SELECT id, title, subtitle, industry, description, project_value, looking_for
-		FROM projects
-		WHERE id = ?
-	`, id)
+	row := m.queryRowStmt(ctx, stmt, getProjectByIDQuery, id)
 
-	var lookingFor sql.NullString
+	var (
+		subtitle    sql.NullString
+		industry    sql.NullString
+		description sql.NullString
+		lookingFor  sql.NullString
+	)
 	// Scan the row into the project struct
-	err := row.Scan(
-		&p.ID, &p.Title, &p.Subtitle, &p.Industry, &p.Description, &p.ProjectValue, &lookingFor,
+	err = row.Scan(
+		&p.ID, &p.Title, &subtitle, &industry, &description, &p.ProjectValue, &lookingFor,
+		&p.CreatedAt, &p.UpdatedAt,
 	)
 
+	p.Subtitle = subtitle.String
+	p.Industry = industry.String
+	p.Description = description.String
 	if lookingFor.Valid {
 		p.LookingFor = parseLookingFor(lookingFor.String)
 	}
 
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, errors.New("project not found")
-		}
 		return nil, err
 	}
 
@@ -190,6 +768,14 @@ func (m *ProjectModel) GetProjectByID(id int) (*dto.Project, error) {
 }
 
 func (m *ProjectModel) GetProjectFullDetails(id int) (*dto.Project, error) {
+	return m.GetProjectFullDetailsContext(context.Background(), id)
+}
+
+// GetProjectFullDetailsContext is GetProjectFullDetails bounded by ctx and the model's query timeout.
+func (m *ProjectModel) GetProjectFullDetailsContext(ctx context.Context, id int) (*dto.Project, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
 	query := `
 		SELECT 
 			p.id, 
@@ -198,19 +784,31 @@ func (m *ProjectModel) GetProjectFullDetails(id int) (*dto.Project, error) {
 			p.industry, 
 			p.description, 
 			p.project_value, 
+			p.currency,
 			p.looking_for, 
 			p.github_link,
+			(SELECT COUNT(*) FROM project_likes pl WHERE pl.project_id = p.id) AS like_count,
+			p.comment_count,
+			p.view_count,
+			p.verified,
+			p.visibility,
+			p.owner_id,
+			p.created_at,
+			p.updated_at,
 			tm.id, 
 			tm.project_id, 
 			tm.profile_url, 
 			tm.title, 
-			tm.role
+			tm.role,
+			tm.is_lead,
+			tm.version
 		FROM projects p
 		LEFT JOIN team_members tm ON p.id = tm.project_id
 		WHERE p.id = ?
 	`
 
-	rows, err := m.db.Query(query, id)
+	db := m.reader()
+	rows, err := m.query(ctx, db, m.q(query), id)
 	if err != nil {
 		return nil, fmt.Errorf("query error: %w", err)
 	}
@@ -226,8 +824,17 @@ func (m *ProjectModel) GetProjectFullDetails(id int) (*dto.Project, error) {
 			industry     sql.NullString
 			description  sql.NullString
 			projectValue float64
+			currency     string
 			lookingFor   sql.NullString // Comma-separated list
 			githubLink   sql.NullString
+			likeCount    sql.NullInt64
+			commentCount sql.NullInt64
+			viewCount    sql.NullInt64
+			verified     sql.NullBool
+			visibility   dto.Visibility
+			ownerID      sql.NullInt64
+			createdAt    dto.Time
+			updatedAt    dto.Time
 		)
 		// Team member columns.
 		var (
@@ -236,6 +843,8 @@ func (m *ProjectModel) GetProjectFullDetails(id int) (*dto.Project, error) {
 			tmProfileURL sql.NullString
 			tmTitle      sql.NullString
 			tmRole       sql.NullString
+			tmIsLead     sql.NullBool
+			tmVersion    sql.NullInt64
 		)
 
 		err = rows.Scan(
@@ -245,13 +854,24 @@ func (m *ProjectModel) GetProjectFullDetails(id int) (*dto.Project, error) {
 			&industry,
 			&description,
 			&projectValue,
+			&currency,
 			&lookingFor,
 			&githubLink,
+			&likeCount,
+			&commentCount,
+			&viewCount,
+			&verified,
+			&visibility,
+			&ownerID,
+			&createdAt,
+			&updatedAt,
 			&tmID,
 			&tmProjectID,
 			&tmProfileURL,
 			&tmTitle,
 			&tmRole,
+			&tmIsLead,
+			&tmVersion,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("scan error: %w", err)
@@ -266,8 +886,17 @@ func (m *ProjectModel) GetProjectFullDetails(id int) (*dto.Project, error) {
 				Industry:     industry.String,
 				Description:  description.String,
 				ProjectValue: projectValue,
+				Currency:     currency,
 				LookingFor:   parseLookingFor(lookingFor.String),
 				GithubLink:   githubLink.String,
+				LikeCount:    int(likeCount.Int64),
+				CommentCount: int(commentCount.Int64),
+				ViewCount:    int(viewCount.Int64),
+				Verified:     verified.Bool,
+				Visibility:   visibility,
+				OwnerID:      int(ownerID.Int64),
+				CreatedAt:    createdAt,
+				UpdatedAt:    updatedAt,
 				TeamMembers:  []dto.TeamMember{},
 				PitchDecks:   []string{},
 				Images:       []string{},
@@ -282,18 +911,25 @@ func (m *ProjectModel) GetProjectFullDetails(id int) (*dto.Project, error) {
 				ProfileURL: tmProfileURL.String,
 				Title:      tmTitle.String,
 				Role:       tmRole.String,
+				IsLead:     tmIsLead.Bool,
+				Version:    int(tmVersion.Int64),
 			}
 			project.TeamMembers = append(project.TeamMembers, teamMember)
 		}
 	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
 
 	if project == nil {
 		return nil, sql.ErrNoRows
 	}
 
+	setProjectLead(project)
+
 	// Now, query for pitch deck file paths.
-	pitchQuery := `SELECT file_path FROM project_pitch_decks WHERE project_id = ?`
-	pitchRows, err := m.db.Query(pitchQuery, id)
+	pitchQuery := `SELECT file_path, preview_path FROM project_pitch_decks WHERE project_id = ?`
+	pitchRows, err := m.query(ctx, db, m.q(pitchQuery), id)
 	if err != nil {
 		return nil, fmt.Errorf("query pitch decks error: %w", err)
 	}
@@ -301,33 +937,69 @@ func (m *ProjectModel) GetProjectFullDetails(id int) (*dto.Project, error) {
 
 	var pitchDecks []string
 	for pitchRows.Next() {
-		var filePath string
-		if err := pitchRows.Scan(&filePath); err != nil {
+		var (
+			filePath    string
+			previewPath sql.NullString
+		)
+		if err := pitchRows.Scan(&filePath, &previewPath); err != nil {
 			return nil, fmt.Errorf("scan pitch deck error: %w", err)
 		}
 		pitchDecks = append(pitchDecks, filePath)
+		if previewPath.Valid {
+			if project.PitchDeckPreviews == nil {
+				project.PitchDeckPreviews = make(map[string]string)
+			}
+			project.PitchDeckPreviews[filePath] = previewPath.String
+		}
 	}
 	// Set the PitchDecks field on the project.
 	project.PitchDecks = pitchDecks
 
 	// Similarly, query for image file paths.
-	imageQuery := `SELECT file_path FROM project_images WHERE project_id = ?`
-	imageRows, err := m.db.Query(imageQuery, id)
+	imageQuery := `SELECT file_path, is_primary FROM project_images WHERE project_id = ? ORDER BY id`
+	imageRows, err := m.query(ctx, db, m.q(imageQuery), id)
 	if err != nil {
 		return nil, fmt.Errorf("query images error: %w", err)
 	}
 	defer imageRows.Close()
 
-	var images []string
+	var (
+		images  []string
+		primary string
+	)
 	for imageRows.Next() {
-		var filePath string
-		if err := imageRows.Scan(&filePath); err != nil {
+		var (
+			filePath  string
+			isPrimary bool
+		)
+		if err := imageRows.Scan(&filePath, &isPrimary); err != nil {
 			return nil, fmt.Errorf("scan image error: %w", err)
 		}
 		images = append(images, filePath)
+		if isPrimary {
+			primary = filePath
+		}
 	}
 	// Set the Images field on the project.
 	project.Images = images
+	project.CoverImage = coverImage(images, primary)
+
+	// Finally, the project's industries.
+	industryRows, err := m.query(ctx, db, m.q(`SELECT industry FROM project_industries WHERE project_id = ? ORDER BY id`), id)
+	if err != nil {
+		return nil, fmt.Errorf("query industries error: %w", err)
+	}
+	defer industryRows.Close()
+
+	var industries []string
+	for industryRows.Next() {
+		var industry string
+		if err := industryRows.Scan(&industry); err != nil {
+			return nil, fmt.Errorf("scan industry error: %w", err)
+		}
+		industries = append(industries, industry)
+	}
+	project.Industries = industries
 
 	return project, nil
 }
@@ -340,6 +1012,12 @@ func parseLookingFor(s string) []string {
 	return splitAndTrim(s, ",")
 }
 
+// nullIfEmpty maps an empty optional value to SQL NULL, so absent values are stored
+// consistently and IS NULL queries find them. Reads map NULL back to "".
+func nullIfEmpty(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
 // splitAndTrim splits a string by the given delimiter and trims spaces.
 func splitAndTrim(s, delim string) []string {
 	parts := strings.Split(s, delim)
@@ -352,38 +1030,74 @@ func splitAndTrim(s, delim string) []string {
 	return result
 }
 
+// InsertTeamMember adds the member to its project and sets its ID and version. A lead member
+// replaces the project's current lead in the same transaction, keeping one lead per project.
 func (m *ProjectModel) InsertTeamMember(member *dto.TeamMember) error {
+	return m.InsertTeamMemberContext(context.Background(), member)
+}
+
+// InsertTeamMemberContext is InsertTeamMember bounded by ctx and the model's query timeout.
+func (m *ProjectModel) InsertTeamMemberContext(ctx context.Context, member *dto.TeamMember) error {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	rollback := func(tx *sql.Tx) {
+		if rErr := tx.Rollback(); rErr != nil {
+			log.Printf("Error rolling back transaction: %v", rErr)
+		}
+	}
+
 	query := `
 		INSERT INTO team_members (
-			project_id, profile_url, title, role
+			project_id, profile_url, title, role, is_lead
 		)
-		VALUES (?, ?, ?, ?)`
-	result, err := m.db.Exec(query, member.ProjectID, member.ProfileURL, member.Title, member.Role)
+		VALUES (?, ?, ?, ?, ?)`
+	id, err := m.insertReturningID(ctx, tx, query, member.ProjectID, nullIfEmpty(member.ProfileURL), nullIfEmpty(member.Title), nullIfEmpty(member.Role), member.IsLead)
 	if err != nil {
+		rollback(tx)
 		log.Println("Error inserting team member:", err)
 		return err
 	}
-	id, err := result.LastInsertId()
-	if err != nil {
+
+	if member.IsLead {
+		if err := m.clearProjectLeadTx(ctx, tx, member.ProjectID, int(id)); err != nil {
+			rollback(tx)
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Println("Error committing transaction:", err)
 		return err
 	}
+
 	member.ID = int(id)
+	member.Version = 1
 	return nil
 }
 
-func (m *ProjectModel) GetTeamMembers(projectID int) ([]*dto.TeamMember, error) {
-	query := `
-		SELECT 
-			id, 
-			project_id, 
-			profile_url, 
-			title, 
-			role
-		FROM team_members
-		WHERE project_id = ?`
+func (m *ProjectModel) GetTeamMembers(projectID, offset, limit int) ([]*dto.TeamMember, error) {
+	return m.GetTeamMembersContext(context.Background(), projectID, offset, limit)
+}
+
+// GetTeamMembersContext is GetTeamMembers bounded by ctx and the model's query timeout.
+func (m *ProjectModel) GetTeamMembersContext(ctx context.Context, projectID, offset, limit int) ([]*dto.TeamMember, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	stmt, err := m.stmts.get(m.reader(), m.q(getTeamMembersQuery))
+	if err != nil {
+		log.Println("Error preparing team members query:", err)
+		return nil, fmt.Errorf("failed to query team members: %w", err)
+	}
 
 	// Execute the query
-	rows, err := m.db.Query(query, projectID)
+	rows, err := m.queryStmt(ctx, stmt, getTeamMembersQuery, projectID, limit, offset)
 	if err != nil {
 		log.Println("Error querying team members:", err)
 		return nil, fmt.Errorf("failed to query team members: %w", err)
@@ -398,17 +1112,27 @@ func (m *ProjectModel) GetTeamMembers(projectID int) ([]*dto.TeamMember, error)
 
 	// Iterate through the rows
 	for rows.Next() {
-		member := &dto.TeamMember{}
+		var (
+			member     = &dto.TeamMember{}
+			profileURL sql.NullString
+			title      sql.NullString
+			role       sql.NullString
+		)
 		if err := rows.Scan(
 			&member.ID,
 			&member.ProjectID,
-			&member.ProfileURL,
-			&member.Title,
-			&member.Role,
+			&profileURL,
+			&title,
+			&role,
+			&member.IsLead,
+			&member.Version,
 		); err != nil {
 			log.Println("Error scanning row:", err)
 			return nil, fmt.Errorf("failed to scan team member: %w", err)
 		}
+		member.ProfileURL = profileURL.String
+		member.Title = title.String
+		member.Role = role.String
 		members = append(members, member)
 	}
 
@@ -421,11 +1145,99 @@ func (m *ProjectModel) GetTeamMembers(projectID int) ([]*dto.TeamMember, error)
 	return members, nil
 }
 
+// GetTeamMemberByID returns the team member with the given ID, or sql.ErrNoRows if there is none.
+func (m *ProjectModel) GetTeamMemberByID(id int) (*dto.TeamMember, error) {
+	return m.GetTeamMemberByIDContext(context.Background(), id)
+}
+
+// GetTeamMemberByIDContext is GetTeamMemberByID bounded by ctx and the model's query timeout.
+func (m *ProjectModel) GetTeamMemberByIDContext(ctx context.Context, id int) (*dto.TeamMember, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT id, project_id, profile_url, title, role, is_lead, version FROM team_members WHERE id = ?`
+
+	var (
+		member     = &dto.TeamMember{}
+		profileURL sql.NullString
+		title      sql.NullString
+		role       sql.NullString
+	)
+	err := m.queryRow(ctx, m.reader(), m.q(query), id).Scan(
+		&member.ID,
+		&member.ProjectID,
+		&profileURL,
+		&title,
+		&role,
+		&member.IsLead,
+		&member.Version,
+	)
+	if err != nil {
+		return nil, err
+	}
+	member.ProfileURL = profileURL.String
+	member.Title = title.String
+	member.Role = role.String
+	return member, nil
+}
+
+// CountTeamMembers returns the total number of team members in the project.
+func (m *ProjectModel) CountTeamMembers(projectID int) (int, error) {
+	return m.CountTeamMembersContext(context.Background(), projectID)
+}
+
+// CountTeamMembersContext is CountTeamMembers bounded by ctx and the model's query timeout.
+func (m *ProjectModel) CountTeamMembersContext(ctx context.Context, projectID int) (int, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	count, _, err := m.TeamMemberListStatsContext(ctx, projectID)
+	return count, err
+}
+
+// TeamMemberListStats returns the number of team members of the project and the newest
+// updated_at among them (zero if there are none).
+func (m *ProjectModel) TeamMemberListStats(projectID int) (int, time.Time, error) {
+	return m.TeamMemberListStatsContext(context.Background(), projectID)
+}
+
+// TeamMemberListStatsContext is TeamMemberListStats bounded by ctx and the model's query timeout.
+func (m *ProjectModel) TeamMemberListStatsContext(ctx context.Context, projectID int) (int, time.Time, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT COUNT(*), MAX(updated_at) FROM team_members WHERE project_id = ?`
+
+	var (
+		count        int
+		lastModified sql.NullTime
+	)
+	if err := m.queryRow(ctx, m.reader(), m.q(query), projectID).Scan(&count, &lastModified); err != nil {
+		log.Println("Error counting team members:", err)
+		return 0, time.Time{}, fmt.Errorf("failed to count team members: %w", err)
+	}
+
+	return count, lastModified.Time, nil
+}
+
 func (m *ProjectModel) ProjectExists(projectID int) (bool, error) {
-	query := `SELECT EXISTS(SELECT 1 FROM projects WHERE id = ?)`
+	return m.ProjectExistsContext(context.Background(), projectID)
+}
+
+// ProjectExistsContext is ProjectExists bounded by ctx and the model's query timeout.
+func (m *ProjectModel) ProjectExistsContext(ctx context.Context, projectID int) (bool, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	// Existence checks gate writes, so they read from the primary to see just-committed rows.
+	stmt, err := m.stmts.get(m.db, m.q(projectExistsQuery))
+	if err != nil {
+		log.Println("Error preparing project exists query:", err)
+		return false, fmt.Errorf("failed to check if project exists: %w", err)
+	}
 
 	var exists bool
-	err := m.db.QueryRow(query, projectID).Scan(&exists)
+	err = m.queryRowStmt(ctx, stmt, projectExistsQuery, projectID).Scan(&exists)
 	if err != nil {
 		log.Println("Error checking if project exists:", err)
 		return false, fmt.Errorf("failed to check if project exists: %w", err)
@@ -434,13 +1246,227 @@ func (m *ProjectModel) ProjectExists(projectID int) (bool, error) {
 	return exists, nil
 }
 
-func (m *ProjectModel) UpdateTeamMemberRole(id int, role string) error {
+// IncrementViewCount atomically bumps the project's view counter.
+func (m *ProjectModel) IncrementViewCount(id int) error {
+	return m.IncrementViewCountContext(context.Background(), id)
+}
+
+// IncrementViewCountContext is IncrementViewCount bounded by ctx and the model's query timeout.
+func (m *ProjectModel) IncrementViewCountContext(ctx context.Context, id int) error {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	// Views aren't edits: keep updated_at, which MySQL would otherwise bump on any update.
+	query := `UPDATE projects SET view_count = view_count + 1, updated_at = updated_at WHERE id = ?`
+
+	if _, err := m.exec(ctx, m.db, m.q(query), id); err != nil {
+		log.Println("Error incrementing view count:", err)
+		return err
+	}
+
+	return nil
+}
+
+// SetProjectLikeTx records (liked) or removes the user's like of the project and returns
+// the project's like count afterwards, or sql.ErrNoRows if the project doesn't exist.
+// Repeating either is a no-op. The denormalized like_count column used by lists is kept
+// in sync without touching updated_at, since a like isn't an edit of the project.
+func (m *ProjectModel) SetProjectLikeTx(projectID, userID int, liked bool) (int, error) {
+	return m.SetProjectLikeTxContext(context.Background(), projectID, userID, liked)
+}
+
+// SetProjectLikeTxContext is SetProjectLikeTx bounded by ctx and the model's query timeout.
+func (m *ProjectModel) SetProjectLikeTxContext(ctx context.Context, projectID, userID int, liked bool) (int, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	rollback := func(tx *sql.Tx) {
+		if rErr := tx.Rollback(); rErr != nil {
+			log.Printf("Error rolling back transaction: %v", rErr)
+		}
+	}
+
+	// Lock the project row so concurrent likes update like_count one at a time.
+	var id int
+	if err := m.queryRow(ctx, tx, m.q(`SELECT id FROM projects WHERE id = ? FOR UPDATE`), projectID).Scan(&id); err != nil {
+		rollback(tx)
+		return 0, err
+	}
+
+	_, count, err := m.setProjectLikeTx(ctx, tx, projectID, userID, liked)
+	if err != nil {
+		rollback(tx)
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Println("Error committing transaction:", err)
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// setProjectLikeTx records or removes the user's like of a project the caller has locked,
+// and returns whether that changed anything and the project's like count afterwards.
+// like_count is only rewritten when the like changed.
+func (m *ProjectModel) setProjectLikeTx(ctx context.Context, tx *sql.Tx, projectID, userID int, liked bool) (bool, int, error) {
+	query := `INSERT IGNORE INTO project_likes (project_id, user_id) VALUES (?, ?)`
+	if m.dialect.IsPostgres() {
+		query = `INSERT INTO project_likes (project_id, user_id) VALUES (?, ?) ON CONFLICT DO NOTHING`
+	}
+	if !liked {
+		query = `DELETE FROM project_likes WHERE project_id = ? AND user_id = ?`
+	}
+	result, err := m.exec(ctx, tx, m.q(query), projectID, userID)
+	if err != nil {
+		log.Println("Error updating project like:", err)
+		return false, 0, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, 0, err
+	}
+
+	var count int
+	if err := m.queryRow(ctx, tx, m.q(`SELECT COUNT(*) FROM project_likes WHERE project_id = ?`), projectID).Scan(&count); err != nil {
+		return false, 0, err
+	}
+
+	if rowsAffected == 0 {
+		return false, count, nil
+	}
+
+	syncQuery := `UPDATE projects SET like_count = ?, updated_at = updated_at WHERE id = ?`
+	if _, err := m.exec(ctx, tx, m.q(syncQuery), count, projectID); err != nil {
+		log.Println("Error syncing like count:", err)
+		return false, 0, err
+	}
+	return true, count, nil
+}
+
+// SetProjectLikesTx applies the user's likes (true) and unlikes (false), keyed by project
+// ID, in one transaction, and returns the outcome for each project that exists. Projects
+// that don't exist are left out of the result. Each change is applied as by
+// SetProjectLikeTx; repeating the current state is reported as unchanged.
+func (m *ProjectModel) SetProjectLikesTx(userID int, likes map[int]bool) (map[int]dto.LikeOutcome, error) {
+	return m.SetProjectLikesTxContext(context.Background(), userID, likes)
+}
+
+// SetProjectLikesTxContext is SetProjectLikesTx bounded by ctx and the model's query timeout.
+func (m *ProjectModel) SetProjectLikesTxContext(ctx context.Context, userID int, likes map[int]bool) (map[int]dto.LikeOutcome, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	outcomes := make(map[int]dto.LikeOutcome, len(likes))
+	if len(likes) == 0 {
+		return outcomes, nil
+	}
+
+	ids := make([]int, 0, len(likes))
+	for id := range likes {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	rollback := func(tx *sql.Tx) {
+		if rErr := tx.Rollback(); rErr != nil {
+			log.Printf("Error rolling back transaction: %v", rErr)
+		}
+	}
+
+	// Lock the projects in ID order, so concurrent batches can't deadlock each other.
+	placeholders, args := inClause(ids)
+	rows, err := m.query(ctx, tx, m.q(fmt.Sprintf(`SELECT id FROM projects WHERE id IN (%s) ORDER BY id FOR UPDATE`, placeholders)), args...)
+	if err != nil {
+		rollback(tx)
+		return nil, err
+	}
+	var existing []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			rollback(tx)
+			return nil, err
+		}
+		existing = append(existing, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		rollback(tx)
+		return nil, err
+	}
+
+	for _, id := range existing {
+		changed, count, err := m.setProjectLikeTx(ctx, tx, id, userID, likes[id])
+		if err != nil {
+			rollback(tx)
+			return nil, err
+		}
+		outcomes[id] = dto.LikeOutcome{Changed: changed, LikeCount: count}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Println("Error committing transaction:", err)
+		return nil, err
+	}
+
+	return outcomes, nil
+}
+
+// ReconcileLikeCounts resets like_count to the number of likes recorded in project_likes
+// wherever the two have drifted apart, and returns how many projects it corrected.
+func (m *ProjectModel) ReconcileLikeCounts() (int64, error) {
+	return m.ReconcileLikeCountsContext(context.Background())
+}
+
+// ReconcileLikeCountsContext is ReconcileLikeCounts bounded by ctx and the model's query timeout.
+func (m *ProjectModel) ReconcileLikeCountsContext(ctx context.Context) (int64, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE projects p
+		SET like_count = (SELECT COUNT(*) FROM project_likes pl WHERE pl.project_id = p.id),
+			updated_at = updated_at
+		WHERE p.like_count <> (SELECT COUNT(*) FROM project_likes pl WHERE pl.project_id = p.id)`
+	result, err := m.exec(ctx, m.db, m.q(query))
+	if err != nil {
+		log.Println("Error reconciling like counts:", err)
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// UpdateTeamMemberRole sets the member's role and increments its version. The update only
+// applies if version is still the stored version, and ErrVersionMismatch is returned
+// otherwise. sql.ErrNoRows means there is no such member.
+func (m *ProjectModel) UpdateTeamMemberRole(id int, role string, version int) error {
+	return m.UpdateTeamMemberRoleContext(context.Background(), id, role, version)
+}
+
+// UpdateTeamMemberRoleContext is UpdateTeamMemberRole bounded by ctx and the model's query timeout.
+func (m *ProjectModel) UpdateTeamMemberRoleContext(ctx context.Context, id int, role string, version int) error {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
 	query := `
         UPDATE team_members
-        SET role = ?, updated_at = CURRENT_TIMESTAMP
-        WHERE id = ?`
+        SET role = ?, version = version + 1, updated_at = CURRENT_TIMESTAMP
+        WHERE id = ? AND version = ?`
 
-	result, err := m.db.Exec(query, role, id)
+	result, err := m.exec(ctx, m.db, m.q(query), nullIfEmpty(role), id, version)
 	if err != nil {
 		log.Println("Error updating team member role:", err)
 		return err
@@ -451,9 +1477,967 @@ func (m *ProjectModel) UpdateTeamMemberRole(id int, role string) error {
 		return err
 	}
 
+	// The version bump changes the row, so no affected rows means no match: either the
+	// member is gone or its version moved on.
+	if rowsAffected == 0 {
+		var exists bool
+		existsQuery := `SELECT EXISTS(SELECT 1 FROM team_members WHERE id = ?)`
+		if err := m.queryRow(ctx, m.db, m.q(existsQuery), id).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return sql.ErrNoRows
+		}
+		return ErrVersionMismatch
+	}
+
+	return nil
+}
+
+// SetTeamMemberLeadTx marks the given member as the lead of the project. Any previous lead
+// is cleared in the same transaction so at most one member per project holds the flag.
+// sql.ErrNoRows means the member isn't on the project.
+func (m *ProjectModel) SetTeamMemberLeadTx(projectID, memberID int) error {
+	return m.SetTeamMemberLeadTxContext(context.Background(), projectID, memberID)
+}
+
+// SetTeamMemberLeadTxContext is SetTeamMemberLeadTx bounded by ctx and the model's query timeout.
+func (m *ProjectModel) SetTeamMemberLeadTxContext(ctx context.Context, projectID, memberID int) error {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	rollback := func(tx *sql.Tx) {
+		if rErr := tx.Rollback(); rErr != nil {
+			log.Printf("Error rolling back transaction: %v", rErr)
+		}
+	}
+
+	if err := m.clearProjectLeadTx(ctx, tx, projectID, memberID); err != nil {
+		rollback(tx)
+		return err
+	}
+
+	setQuery := `
+		UPDATE team_members
+		SET is_lead = TRUE, version = version + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND project_id = ? AND is_lead = FALSE`
+	result, err := m.exec(ctx, tx, m.q(setQuery), memberID, projectID)
+	if err != nil {
+		rollback(tx)
+		log.Println("Error setting project lead:", err)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		rollback(tx)
+		return err
+	}
+
+	// MySQL reports zero affected rows when the member was already the lead,
+	// so confirm the member actually belongs to the project before giving up.
 	if rowsAffected == 0 {
-		return errors.New("no rows affected, possibly invalid team member ID")
+		var exists bool
+		existsQuery := `SELECT EXISTS(SELECT 1 FROM team_members WHERE id = ? AND project_id = ?)`
+		if err := m.queryRow(ctx, tx, m.q(existsQuery), memberID, projectID).Scan(&exists); err != nil {
+			rollback(tx)
+			return err
+		}
+		if !exists {
+			rollback(tx)
+			return sql.ErrNoRows
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Println("Error committing transaction:", err)
+		return err
 	}
 
 	return nil
 }
+
+// clearProjectLeadTx removes the lead flag from every member of the project but memberID.
+func (m *ProjectModel) clearProjectLeadTx(ctx context.Context, tx *sql.Tx, projectID, memberID int) error {
+	query := `
+		UPDATE team_members
+		SET is_lead = FALSE, version = version + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE project_id = ? AND is_lead = TRUE AND id <> ?`
+	if _, err := m.exec(ctx, tx, m.q(query), projectID, memberID); err != nil {
+		log.Println("Error clearing project lead:", err)
+		return err
+	}
+	return nil
+}
+
+// DeleteProjectImagesTx removes every image row of the project and returns the deleted file paths.
+func (m *ProjectModel) DeleteProjectImagesTx(projectID int) ([]string, error) {
+	return m.DeleteProjectImagesTxContext(context.Background(), projectID)
+}
+
+// DeleteProjectImagesTxContext is DeleteProjectImagesTx bounded by ctx and the model's query timeout.
+func (m *ProjectModel) DeleteProjectImagesTxContext(ctx context.Context, projectID int) ([]string, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	return m.deleteProjectFilesTx(ctx, "project_images", projectID)
+}
+
+// DeleteProjectPitchDecksTx removes every pitch deck row of the project and returns the
+// deleted pitch deck paths along with the paths of their preview images.
+func (m *ProjectModel) DeleteProjectPitchDecksTx(projectID int) ([]string, []string, error) {
+	return m.DeleteProjectPitchDecksTxContext(context.Background(), projectID)
+}
+
+// DeleteProjectPitchDecksTxContext is DeleteProjectPitchDecksTx bounded by ctx and the model's query timeout.
+func (m *ProjectModel) DeleteProjectPitchDecksTxContext(ctx context.Context, projectID int) ([]string, []string, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rollback := func(tx *sql.Tx) {
+		if rErr := tx.Rollback(); rErr != nil {
+			log.Printf("Error rolling back transaction: %v", rErr)
+		}
+	}
+
+	paths, err := m.selectFilePathsTx(ctx, tx, "project_pitch_decks", projectID)
+	if err != nil {
+		rollback(tx)
+		return nil, nil, err
+	}
+	previews, err := m.selectPreviewPathsTx(ctx, tx, projectID)
+	if err != nil {
+		rollback(tx)
+		return nil, nil, err
+	}
+
+	if _, err := m.exec(ctx, tx, m.q(`DELETE FROM project_pitch_decks WHERE project_id = ?`), projectID); err != nil {
+		rollback(tx)
+		log.Printf("Error deleting project_pitch_decks: %v", err)
+		return nil, nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Println("Error committing transaction:", err)
+		return nil, nil, err
+	}
+
+	return paths, previews, nil
+}
+
+// SetPrimaryImageTx marks the project's image with the given stored filename as its cover.
+// The previous primary image is cleared in the same transaction, with the project row
+// locked, so at most one image per project holds the flag. It returns sql.ErrNoRows when
+// the image doesn't belong to the project.
+func (m *ProjectModel) SetPrimaryImageTx(projectID int, filename string) error {
+	return m.SetPrimaryImageTxContext(context.Background(), projectID, filename)
+}
+
+// SetPrimaryImageTxContext is SetPrimaryImageTx bounded by ctx and the model's query timeout.
+func (m *ProjectModel) SetPrimaryImageTxContext(ctx context.Context, projectID int, filename string) error {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	rollback := func(tx *sql.Tx) {
+		if rErr := tx.Rollback(); rErr != nil {
+			log.Printf("Error rolling back transaction: %v", rErr)
+		}
+	}
+
+	// Serialize concurrent changes of the cover; MySQL has no constraint to fall back on.
+	var id int
+	if err := m.queryRow(ctx, tx, m.q(`SELECT id FROM projects WHERE id = ? FOR UPDATE`), projectID).Scan(&id); err != nil {
+		rollback(tx)
+		return err
+	}
+
+	var exists bool
+	existsQuery := `SELECT EXISTS(SELECT 1 FROM project_images WHERE project_id = ? AND file_path = ?)`
+	if err := m.queryRow(ctx, tx, m.q(existsQuery), projectID, filename).Scan(&exists); err != nil {
+		rollback(tx)
+		return err
+	}
+	if !exists {
+		rollback(tx)
+		return sql.ErrNoRows
+	}
+
+	// Clear before setting, so Postgres' unique index on primary images never sees two.
+	clearQuery := `
+		UPDATE project_images
+		SET is_primary = FALSE
+		WHERE project_id = ? AND is_primary = TRUE AND file_path <> ?`
+	if _, err := m.exec(ctx, tx, m.q(clearQuery), projectID, filename); err != nil {
+		rollback(tx)
+		log.Println("Error clearing primary image:", err)
+		return err
+	}
+
+	setQuery := `UPDATE project_images SET is_primary = TRUE WHERE project_id = ? AND file_path = ?`
+	if _, err := m.exec(ctx, tx, m.q(setQuery), projectID, filename); err != nil {
+		rollback(tx)
+		log.Println("Error setting primary image:", err)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Println("Error committing transaction:", err)
+		return err
+	}
+
+	return nil
+}
+
+// DeleteProjectFileTx removes a single image or pitch deck row of the project by its stored
+// filename and returns the files to delete from storage, including a deck's preview image.
+// It returns sql.ErrNoRows when the file doesn't belong to the project.
+func (m *ProjectModel) DeleteProjectFileTx(projectID int, filename string) ([]dto.FileResult, error) {
+	return m.DeleteProjectFileTxContext(context.Background(), projectID, filename)
+}
+
+// DeleteProjectFileTxContext is DeleteProjectFileTx bounded by ctx and the model's query timeout.
+func (m *ProjectModel) DeleteProjectFileTxContext(ctx context.Context, projectID int, filename string) ([]dto.FileResult, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	rollback := func(tx *sql.Tx) {
+		if rErr := tx.Rollback(); rErr != nil {
+			log.Printf("Error rolling back transaction: %v", rErr)
+		}
+	}
+
+	// Scoping every statement to the project keeps one project from removing another's files.
+	result, err := m.exec(ctx, tx, m.q(`DELETE FROM project_images WHERE project_id = ? AND file_path = ?`), projectID, filename)
+	if err != nil {
+		rollback(tx)
+		log.Printf("Error deleting project image: %v", err)
+		return nil, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		rollback(tx)
+		return nil, err
+	}
+
+	var files []dto.FileResult
+	if rowsAffected > 0 {
+		files = append(files, dto.FileResult{FileType: dto.FileTypeImages, Filename: filename})
+	} else {
+		rows, err := m.query(ctx, tx, m.q(`SELECT preview_path FROM project_pitch_decks WHERE project_id = ? AND file_path = ? FOR UPDATE`), projectID, filename)
+		if err != nil {
+			rollback(tx)
+			log.Printf("Error selecting pitch deck: %v", err)
+			return nil, err
+		}
+		for rows.Next() {
+			var preview sql.NullString
+			if err := rows.Scan(&preview); err != nil {
+				rows.Close()
+				rollback(tx)
+				return nil, fmt.Errorf("scan pitch deck error: %w", err)
+			}
+			if len(files) == 0 {
+				files = append(files, dto.FileResult{FileType: dto.FileTypePDFs, Filename: filename})
+			}
+			// Pitch deck previews are stored with the images.
+			if preview.Valid {
+				files = append(files, dto.FileResult{FileType: dto.FileTypeImages, Filename: preview.String, PreviewOf: filename})
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			rollback(tx)
+			return nil, fmt.Errorf("row iteration error: %w", err)
+		}
+		if len(files) == 0 {
+			rollback(tx)
+			return nil, sql.ErrNoRows
+		}
+
+		if _, err := m.exec(ctx, tx, m.q(`DELETE FROM project_pitch_decks WHERE project_id = ? AND file_path = ?`), projectID, filename); err != nil {
+			rollback(tx)
+			log.Printf("Error deleting project pitch deck: %v", err)
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Println("Error committing transaction:", err)
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// deleteProjectFilesTx locks and deletes all file rows of a project in the given table,
+// returning the paths that were removed so the caller can delete the files after commit.
+func (m *ProjectModel) deleteProjectFilesTx(ctx context.Context, table string, projectID int) ([]string, error) {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	rollback := func(tx *sql.Tx) {
+		if rErr := tx.Rollback(); rErr != nil {
+			log.Printf("Error rolling back transaction: %v", rErr)
+		}
+	}
+
+	paths, err := m.selectFilePathsTx(ctx, tx, table, projectID)
+	if err != nil {
+		rollback(tx)
+		return nil, err
+	}
+
+	if _, err := m.exec(ctx, tx, m.q(fmt.Sprintf(`DELETE FROM %s WHERE project_id = ?`, table)), projectID); err != nil {
+		rollback(tx)
+		log.Printf("Error deleting %s: %v", table, err)
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Println("Error committing transaction:", err)
+		return nil, err
+	}
+
+	return paths, nil
+}
+
+// selectFilePathsTx locks and returns the file paths of a project stored in the given table.
+func (m *ProjectModel) selectFilePathsTx(ctx context.Context, tx *sql.Tx, table string, projectID int) ([]string, error) {
+	rows, err := m.query(ctx, tx, m.q(fmt.Sprintf(`SELECT file_path FROM %s WHERE project_id = ? FOR UPDATE`, table)), projectID)
+	if err != nil {
+		log.Printf("Error selecting %s: %v", table, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var paths []string
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			return nil, fmt.Errorf("scan %s error: %w", table, err)
+		}
+		paths = append(paths, path)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+	return paths, nil
+}
+
+// selectPreviewPathsTx returns the preview image paths of the project's pitch decks.
+// Callers must already hold the pitch deck rows locked via selectFilePathsTx.
+func (m *ProjectModel) selectPreviewPathsTx(ctx context.Context, tx *sql.Tx, projectID int) ([]string, error) {
+	rows, err := m.query(ctx, tx, m.q(`SELECT preview_path FROM project_pitch_decks WHERE project_id = ? AND preview_path IS NOT NULL`), projectID)
+	if err != nil {
+		log.Printf("Error selecting pitch deck previews: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var paths []string
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			return nil, fmt.Errorf("scan pitch deck preview error: %w", err)
+		}
+		paths = append(paths, path)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+	return paths, nil
+}
+
+// DeleteProjectTx removes the project together with its team members, pitch decks and
+// images in a single transaction. It returns the file paths that were referenced so the
+// caller can delete the stored files after commit, or sql.ErrNoRows if the project doesn't exist.
+func (m *ProjectModel) DeleteProjectTx(id int) (dto.SavedFiles, error) {
+	return m.DeleteProjectTxContext(context.Background(), id)
+}
+
+// DeleteProjectTxContext is DeleteProjectTx bounded by ctx and the model's query timeout.
+func (m *ProjectModel) DeleteProjectTxContext(ctx context.Context, id int) (dto.SavedFiles, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return dto.SavedFiles{}, err
+	}
+
+	rollback := func(tx *sql.Tx) {
+		if rErr := tx.Rollback(); rErr != nil {
+			log.Printf("Error rolling back transaction: %v", rErr)
+		}
+	}
+
+	// Lock the project row so concurrent inserts of files can't slip in.
+	var projectID int
+	if err := m.queryRow(ctx, tx, m.q(`SELECT id FROM projects WHERE id = ? FOR UPDATE`), id).Scan(&projectID); err != nil {
+		rollback(tx)
+		return dto.SavedFiles{}, err
+	}
+
+	var files dto.SavedFiles
+	if files.PDFFiles, err = m.selectFilePathsTx(ctx, tx, "project_pitch_decks", id); err != nil {
+		rollback(tx)
+		return dto.SavedFiles{}, err
+	}
+	if files.ImageFiles, err = m.selectFilePathsTx(ctx, tx, "project_images", id); err != nil {
+		rollback(tx)
+		return dto.SavedFiles{}, err
+	}
+	// Pitch deck previews are stored alongside the images.
+	previews, err := m.selectPreviewPathsTx(ctx, tx, id)
+	if err != nil {
+		rollback(tx)
+		return dto.SavedFiles{}, err
+	}
+	files.ImageFiles = append(files.ImageFiles, previews...)
+
+	// Child rows cascade, but delete them explicitly so the intent doesn't hinge on the schema.
+	queries := []string{
+		`DELETE FROM team_members WHERE project_id = ?`,
+		`DELETE FROM project_pitch_decks WHERE project_id = ?`,
+		`DELETE FROM project_images WHERE project_id = ?`,
+		`DELETE FROM project_industries WHERE project_id = ?`,
+		`DELETE FROM project_likes WHERE project_id = ?`,
+		`DELETE FROM projects WHERE id = ?`,
+	}
+	for _, query := range queries {
+		if _, err := m.exec(ctx, tx, m.q(query), id); err != nil {
+			rollback(tx)
+			log.Println("Error deleting project:", err)
+			return dto.SavedFiles{}, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Println("Error committing transaction:", err)
+		return dto.SavedFiles{}, err
+	}
+
+	return files, nil
+}
+
+// DeleteTeamMember removes the team member, returning sql.ErrNoRows if it doesn't exist.
+func (m *ProjectModel) DeleteTeamMember(id int) error {
+	return m.DeleteTeamMemberContext(context.Background(), id)
+}
+
+// DeleteTeamMemberContext is DeleteTeamMember bounded by ctx and the model's query timeout.
+func (m *ProjectModel) DeleteTeamMemberContext(ctx context.Context, id int) error {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	query := `DELETE FROM team_members WHERE id = ?`
+
+	result, err := m.exec(ctx, m.db, m.q(query), id)
+	if err != nil {
+		log.Println("Error deleting team member:", err)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// ReassignTeamMembersTx moves the members to the target project in one transaction and
+// returns how many of them were not already on it. Moved members lose their lead flag,
+// since the target project keeps its own lead. Each move is recorded in
+// team_member_reassignments, with reassignedBy as the acting user, in the same transaction.
+// It returns sql.ErrNoRows, changing nothing, if any of the members doesn't exist.
+func (m *ProjectModel) ReassignTeamMembersTx(memberIDs []int, targetProjectID, reassignedBy int) (int, error) {
+	return m.ReassignTeamMembersTxContext(context.Background(), memberIDs, targetProjectID, reassignedBy)
+}
+
+// ReassignTeamMembersTxContext is ReassignTeamMembersTx bounded by ctx and the model's query timeout.
+func (m *ProjectModel) ReassignTeamMembersTxContext(ctx context.Context, memberIDs []int, targetProjectID, reassignedBy int) (int, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	rollback := func(tx *sql.Tx) {
+		if rErr := tx.Rollback(); rErr != nil {
+			log.Printf("Error rolling back transaction: %v", rErr)
+		}
+	}
+
+	placeholders, args := inClause(memberIDs)
+
+	// Lock the members and note where the moving ones come from. The rows are read back
+	// rather than aggregated because Postgres doesn't allow FOR UPDATE with aggregates.
+	rows, err := m.query(ctx, tx, m.q(fmt.Sprintf(`SELECT id, project_id FROM team_members WHERE id IN (%s) FOR UPDATE`, placeholders)), args...)
+	if err != nil {
+		rollback(tx)
+		return 0, err
+	}
+	var (
+		found int
+		audit []interface{}
+	)
+	for rows.Next() {
+		var memberID, projectID int
+		if err := rows.Scan(&memberID, &projectID); err != nil {
+			rows.Close()
+			rollback(tx)
+			return 0, err
+		}
+		found++
+		if projectID != targetProjectID {
+			audit = append(audit, memberID, projectID, targetProjectID, reassignedBy)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		rollback(tx)
+		return 0, err
+	}
+	if found != len(memberIDs) {
+		rollback(tx)
+		return 0, sql.ErrNoRows
+	}
+
+	updateQuery := fmt.Sprintf(`
+		UPDATE team_members
+		SET project_id = ?, is_lead = FALSE, version = version + 1
+		WHERE id IN (%s) AND project_id <> ?`, placeholders)
+	updateArgs := append([]interface{}{targetProjectID}, args...)
+	updateArgs = append(updateArgs, targetProjectID)
+	if _, err := m.exec(ctx, tx, m.q(updateQuery), updateArgs...); err != nil {
+		rollback(tx)
+		log.Println("Error reassigning team members:", err)
+		return 0, err
+	}
+
+	moving := len(audit) / 4
+	if moving > 0 {
+		auditQuery := `
+			INSERT INTO team_member_reassignments (team_member_id, from_project_id, to_project_id, reassigned_by)
+			VALUES ` + strings.TrimSuffix(strings.Repeat("(?, ?, ?, ?), ", moving), ", ")
+		if _, err := m.exec(ctx, tx, m.q(auditQuery), audit...); err != nil {
+			rollback(tx)
+			log.Println("Error recording team member reassignment:", err)
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Println("Error committing transaction:", err)
+		return 0, err
+	}
+
+	return moving, nil
+}
+
+// UpdateProject applies the fields set in the patch to the project in one transaction. Cleared
+// fields are stored as NULL and a set industries list replaces the stored one. The caller is
+// responsible for validating the patch, keeping industry and industries in step, and checking
+// the project's existence.
+func (m *ProjectModel) UpdateProject(id int, patch dto.ProjectPatch) error {
+	return m.UpdateProjectContext(context.Background(), id, patch)
+}
+
+// UpdateProjectContext is UpdateProject bounded by ctx and the model's query timeout.
+func (m *ProjectModel) UpdateProjectContext(ctx context.Context, id int, patch dto.ProjectPatch) error {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	var (
+		assignments []string
+		args        []interface{}
+	)
+
+	// Empty strings are stored as NULL, like on insert.
+	set := func(column string, null bool, value interface{}) {
+		assignments = append(assignments, column+" = ?")
+		if s, ok := value.(string); null || (ok && s == "") {
+			args = append(args, nil)
+		} else {
+			args = append(args, value)
+		}
+	}
+
+	if patch.Title.Set {
+		set("title", patch.Title.Null, patch.Title.Value)
+	}
+	if patch.Subtitle.Set {
+		set("subtitle", patch.Subtitle.Null, patch.Subtitle.Value)
+	}
+	if patch.Industry.Set {
+		set("industry", patch.Industry.Null, patch.Industry.Value)
+	}
+	if patch.Description.Set {
+		set("description", patch.Description.Null, patch.Description.Value)
+	}
+	if patch.ProjectValue.Set {
+		set("project_value", patch.ProjectValue.Null, patch.ProjectValue.Value)
+	}
+	if patch.Currency.Set {
+		set("currency", false, patch.Currency.Value)
+	}
+	if patch.LookingFor.Set {
+		set("looking_for", patch.LookingFor.Null || len(patch.LookingFor.Value) == 0, strings.Join(patch.LookingFor.Value, ","))
+	}
+	if patch.GithubLink.Set {
+		set("github_link", patch.GithubLink.Null, patch.GithubLink.Value)
+	}
+	if patch.Visibility.Set {
+		set("visibility", false, patch.Visibility.Value)
+	}
+
+	// Nothing to change.
+	if len(assignments) == 0 && !patch.Industries.Set {
+		return nil
+	}
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	rollback := func(tx *sql.Tx) {
+		if rErr := tx.Rollback(); rErr != nil {
+			log.Printf("Error rolling back transaction: %v", rErr)
+		}
+	}
+
+	if len(assignments) > 0 {
+		// Set explicitly: only MySQL bumps updated_at on update by itself.
+		assignments = append(assignments, "updated_at = CURRENT_TIMESTAMP")
+		query := `UPDATE projects SET ` + strings.Join(assignments, ", ") + ` WHERE id = ?`
+		args = append(args, id)
+
+		if _, err := m.exec(ctx, tx, m.q(query), args...); err != nil {
+			rollback(tx)
+			log.Println("Error updating project:", err)
+			return err
+		}
+	}
+
+	if patch.Industries.Set {
+		if err := m.replaceProjectIndustriesTx(ctx, tx, id, patch.Industries.Value); err != nil {
+			rollback(tx)
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Println("Error committing transaction:", err)
+		return err
+	}
+
+	return nil
+}
+
+// GetProjectVisibility returns the project's visibility, or sql.ErrNoRows if it doesn't exist.
+func (m *ProjectModel) GetProjectVisibility(id int) (dto.Visibility, error) {
+	return m.GetProjectVisibilityContext(context.Background(), id)
+}
+
+// GetProjectVisibilityContext is GetProjectVisibility bounded by ctx and the model's query timeout.
+func (m *ProjectModel) GetProjectVisibilityContext(ctx context.Context, id int) (dto.Visibility, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	var visibility dto.Visibility
+	if err := m.queryRow(ctx, m.reader(), m.q(`SELECT visibility FROM projects WHERE id = ?`), id).Scan(&visibility); err != nil {
+		return "", err
+	}
+	return visibility, nil
+}
+
+// GetProjectOwner returns the project's owner, 0 for projects from before owners were
+// recorded, and its visibility, or sql.ErrNoRows if it doesn't exist. It reads the
+// primary, as it backs authorization checks.
+func (m *ProjectModel) GetProjectOwner(id int) (int, dto.Visibility, error) {
+	return m.GetProjectOwnerContext(context.Background(), id)
+}
+
+// GetProjectOwnerContext is GetProjectOwner bounded by ctx and the model's query timeout.
+func (m *ProjectModel) GetProjectOwnerContext(ctx context.Context, id int) (int, dto.Visibility, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	var (
+		ownerID    sql.NullInt64
+		visibility dto.Visibility
+	)
+	if err := m.queryRow(ctx, m.db, m.q(`SELECT owner_id, visibility FROM projects WHERE id = ?`), id).Scan(&ownerID, &visibility); err != nil {
+		return 0, "", err
+	}
+	return int(ownerID.Int64), visibility, nil
+}
+
+// GetTeamMemberProjectID returns the ID of the member's project, or sql.ErrNoRows if there
+// is no such member. It reads the primary, as it backs authorization checks.
+func (m *ProjectModel) GetTeamMemberProjectID(id int) (int, error) {
+	return m.GetTeamMemberProjectIDContext(context.Background(), id)
+}
+
+// GetTeamMemberProjectIDContext is GetTeamMemberProjectID bounded by ctx and the model's query timeout.
+func (m *ProjectModel) GetTeamMemberProjectIDContext(ctx context.Context, id int) (int, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	var projectID int
+	if err := m.queryRow(ctx, m.db, m.q(`SELECT project_id FROM team_members WHERE id = ?`), id).Scan(&projectID); err != nil {
+		return 0, err
+	}
+	return projectID, nil
+}
+
+// GetPreviewTokenVersion returns the project's current preview token version, or
+// sql.ErrNoRows if the project doesn't exist. It reads the primary so a revocation
+// takes effect immediately.
+func (m *ProjectModel) GetPreviewTokenVersion(id int) (int, error) {
+	return m.GetPreviewTokenVersionContext(context.Background(), id)
+}
+
+// GetPreviewTokenVersionContext is GetPreviewTokenVersion bounded by ctx and the model's query timeout.
+func (m *ProjectModel) GetPreviewTokenVersionContext(ctx context.Context, id int) (int, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	var version int
+	if err := m.queryRow(ctx, m.db, m.q(`SELECT preview_token_version FROM projects WHERE id = ?`), id).Scan(&version); err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+// BumpPreviewTokenVersion invalidates every preview token issued for the project so far.
+// It returns sql.ErrNoRows if the project doesn't exist.
+func (m *ProjectModel) BumpPreviewTokenVersion(id int) error {
+	return m.BumpPreviewTokenVersionContext(context.Background(), id)
+}
+
+// BumpPreviewTokenVersionContext is BumpPreviewTokenVersion bounded by ctx and the model's query timeout.
+func (m *ProjectModel) BumpPreviewTokenVersionContext(ctx context.Context, id int) error {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	query := `UPDATE projects SET preview_token_version = preview_token_version + 1, updated_at = updated_at WHERE id = ?`
+	result, err := m.exec(ctx, m.db, m.q(query), id)
+	if err != nil {
+		log.Println("Error revoking preview tokens:", err)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetFileReference returns the project that references the stored file, along with the
+// name the file was uploaded under, or sql.ErrNoRows when no project references it.
+func (m *ProjectModel) GetFileReference(filename string) (dto.FileReference, error) {
+	return m.GetFileReferenceContext(context.Background(), filename)
+}
+
+// GetFileReferenceContext is GetFileReference bounded by ctx and the model's query timeout.
+func (m *ProjectModel) GetFileReferenceContext(ctx context.Context, filename string) (dto.FileReference, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	// Previews are generated rather than uploaded, so they have no original name.
+	query := `
+		SELECT p.id, p.visibility, f.original_name
+		FROM projects p
+		JOIN (
+			SELECT project_id, original_name FROM project_images WHERE file_path = ?
+			UNION
+			SELECT project_id, original_name FROM project_pitch_decks WHERE file_path = ?
+			UNION
+			SELECT project_id, NULL FROM project_pitch_decks WHERE preview_path = ?
+		) f ON f.project_id = p.id
+		LIMIT 1`
+
+	var (
+		ref          dto.FileReference
+		originalName sql.NullString
+	)
+	if err := m.queryRow(ctx, m.reader(), m.q(query), filename, filename, filename).Scan(&ref.ProjectID, &ref.Visibility, &originalName); err != nil {
+		return dto.FileReference{}, err
+	}
+	ref.OriginalName = originalName.String
+	return ref, nil
+}
+
+// referencedFilesBatch caps how many filenames ReferencedFiles checks per query.
+const referencedFilesBatch = 500
+
+// ReferencedFiles reports which of the stored filenames a project references, as an image,
+// a pitch deck or a deck's preview.
+func (m *ProjectModel) ReferencedFiles(names []string) (map[string]bool, error) {
+	return m.ReferencedFilesContext(context.Background(), names)
+}
+
+// ReferencedFilesContext is ReferencedFiles bounded by ctx and the model's query timeout.
+func (m *ProjectModel) ReferencedFilesContext(ctx context.Context, names []string) (map[string]bool, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	referenced := make(map[string]bool)
+	for start := 0; start < len(names); start += referencedFilesBatch {
+		batch := names[start:min(start+referencedFilesBatch, len(names))]
+		placeholders, args := inClause(batch)
+
+		query := fmt.Sprintf(`
+			SELECT file_path FROM project_images WHERE file_path IN (%[1]s)
+			UNION
+			SELECT file_path FROM project_pitch_decks WHERE file_path IN (%[1]s)
+			UNION
+			SELECT preview_path FROM project_pitch_decks WHERE preview_path IN (%[1]s)`, placeholders)
+
+		allArgs := make([]interface{}, 0, len(args)*3)
+		allArgs = append(allArgs, args...)
+		allArgs = append(allArgs, args...)
+		allArgs = append(allArgs, args...)
+
+		rows, err := m.query(ctx, m.reader(), m.q(query), allArgs...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query file references: %w", err)
+		}
+		for rows.Next() {
+			var name string
+			if err := rows.Scan(&name); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan file reference: %w", err)
+			}
+			referenced[name] = true
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, fmt.Errorf("row iteration error: %w", err)
+		}
+	}
+	return referenced, nil
+}
+
+// GetDistinctIndustries returns the industries listed by public projects, sorted. They come
+// from project_industries rather than projects.industry so that secondary industries, which
+// the industry filter also matches, are included.
+func (m *ProjectModel) GetDistinctIndustries() ([]string, error) {
+	return m.GetDistinctIndustriesContext(context.Background())
+}
+
+// GetDistinctIndustriesContext is GetDistinctIndustries bounded by ctx and the model's query timeout.
+func (m *ProjectModel) GetDistinctIndustriesContext(ctx context.Context) ([]string, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT DISTINCT pi.industry
+		FROM project_industries pi
+		JOIN projects p ON p.id = pi.project_id
+		WHERE p.visibility = ? AND pi.industry != ''
+		ORDER BY pi.industry`
+
+	rows, err := m.query(ctx, m.reader(), m.q(query), dto.VisibilityPublic)
+	if err != nil {
+		log.Println("Error listing industries:", err)
+		return nil, fmt.Errorf("failed to list industries: %w", err)
+	}
+	defer rows.Close()
+
+	industries := []string{}
+	for rows.Next() {
+		var industry string
+		if err := rows.Scan(&industry); err != nil {
+			return nil, fmt.Errorf("failed to scan industry: %w", err)
+		}
+		industries = append(industries, industry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return industries, nil
+}
+
+// SumValueByIndustry returns the total, average and count of project_value grouped by
+// industry, largest total first. Only public projects are counted, as in lists. Projects
+// without an industry are grouped under "". Amounts are summed as stored, without
+// converting between currencies.
+func (m *ProjectModel) SumValueByIndustry() ([]dto.IndustryValueStat, error) {
+	return m.SumValueByIndustryContext(context.Background())
+}
+
+// SumValueByIndustryContext is SumValueByIndustry bounded by ctx and the model's query timeout.
+func (m *ProjectModel) SumValueByIndustryContext(ctx context.Context) ([]dto.IndustryValueStat, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT
+			COALESCE(industry, '') AS industry_name,
+			COALESCE(SUM(project_value), 0),
+			COALESCE(AVG(project_value), 0),
+			COUNT(*)
+		FROM projects
+		WHERE visibility = ?
+		GROUP BY industry_name
+		ORDER BY 2 DESC, industry_name`
+
+	rows, err := m.query(ctx, m.reader(), m.q(query), dto.VisibilityPublic)
+	if err != nil {
+		log.Println("Error aggregating project value by industry:", err)
+		return nil, fmt.Errorf("failed to aggregate project value: %w", err)
+	}
+	defer rows.Close()
+
+	stats := []dto.IndustryValueStat{}
+	for rows.Next() {
+		var stat dto.IndustryValueStat
+		if err := rows.Scan(&stat.Industry, &stat.Total, &stat.Average, &stat.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan industry stat: %w", err)
+		}
+		stats = append(stats, stat)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,253 @@
+package models
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tarsuniversecentral/project-module/internal/dto"
+	"github.com/tarsuniversecentral/project-module/pkg/database"
+)
+
+func TestBuildProjectFilter(t *testing.T) {
+	filter := dto.ProjectFilter{LookingFor: []string{"Invest", "Mentor"}}
+	wantArgs := []interface{}{dto.VisibilityPublic, "Invest", "Mentor"}
+
+	tests := []struct {
+		driver string
+		want   string
+	}{
+		{database.DriverMySQL, " WHERE visibility = ? AND FIND_IN_SET(?, looking_for) > 0 AND FIND_IN_SET(?, looking_for) > 0"},
+		{database.DriverPostgres, " WHERE visibility = ? AND ? = ANY(string_to_array(looking_for, ',')) AND ? = ANY(string_to_array(looking_for, ','))"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.driver, func(t *testing.T) {
+			dialect, err := database.NewDialect(tt.driver)
+			if err != nil {
+				t.Fatal(err)
+			}
+			where, args := buildProjectFilter(dialect, filter)
+			if where != tt.want {
+				t.Errorf("where = %q, want %q", where, tt.want)
+			}
+			if !reflect.DeepEqual(args, wantArgs) {
+				t.Errorf("args = %v, want %v", args, wantArgs)
+			}
+		})
+	}
+}
+
+func TestIncrementViewCountKeepsUpdatedAt(t *testing.T) {
+	m := openTestModel(t)
+	ctx := context.Background()
+
+	p := &dto.Project{Title: "Viewed", Currency: "USD", Visibility: dto.VisibilityPublic, OwnerID: 7}
+	if err := m.CreateProjectTxContext(ctx, p, ""); err != nil {
+		t.Fatalf("CreateProjectTxContext: %v", err)
+	}
+	filter := dto.ProjectFilter{OwnerID: p.OwnerID}
+
+	_, before, err := m.ProjectListStatsContext(ctx, filter)
+	if err != nil {
+		t.Fatalf("ProjectListStatsContext: %v", err)
+	}
+
+	// TIMESTAMP has whole-second precision; wait so a bumped updated_at would differ.
+	time.Sleep(1100 * time.Millisecond)
+
+	if err := m.IncrementViewCountContext(ctx, p.ID); err != nil {
+		t.Fatalf("IncrementViewCountContext: %v", err)
+	}
+
+	_, after, err := m.ProjectListStatsContext(ctx, filter)
+	if err != nil {
+		t.Fatalf("ProjectListStatsContext: %v", err)
+	}
+	if !after.Equal(before) {
+		t.Errorf("MAX(updated_at) changed on a view: before %v, after %v", before, after)
+	}
+}
+
+func TestReassignTeamMembersRecordsAudit(t *testing.T) {
+	m := openTestModel(t)
+	ctx := context.Background()
+
+	from := &dto.Project{Title: "From", Currency: "USD", Visibility: dto.VisibilityPublic, OwnerID: 7}
+	to := &dto.Project{Title: "To", Currency: "USD", Visibility: dto.VisibilityPublic, OwnerID: 7}
+	for _, p := range []*dto.Project{from, to} {
+		if err := m.CreateProjectTxContext(ctx, p, ""); err != nil {
+			t.Fatalf("CreateProjectTxContext: %v", err)
+		}
+	}
+	moving := &dto.TeamMember{ProjectID: from.ID, Title: "Moving"}
+	staying := &dto.TeamMember{ProjectID: to.ID, Title: "Already there"}
+	for _, member := range []*dto.TeamMember{moving, staying} {
+		if err := m.InsertTeamMemberContext(ctx, member); err != nil {
+			t.Fatalf("InsertTeamMemberContext: %v", err)
+		}
+	}
+
+	moved, err := m.ReassignTeamMembersTxContext(ctx, []int{moving.ID, staying.ID}, to.ID, 99)
+	if err != nil {
+		t.Fatalf("ReassignTeamMembersTxContext: %v", err)
+	}
+	if moved != 1 {
+		t.Errorf("moved = %d, want 1", moved)
+	}
+
+	var memberID, fromID, toID, by int
+	query := `SELECT team_member_id, from_project_id, to_project_id, reassigned_by FROM team_member_reassignments`
+	if err := m.db.QueryRow(m.q(query)).Scan(&memberID, &fromID, &toID, &by); err != nil {
+		t.Fatalf("reading audit row: %v", err)
+	}
+	if memberID != moving.ID || fromID != from.ID || toID != to.ID || by != 99 {
+		t.Errorf("audit row = (%d, %d, %d, %d), want (%d, %d, %d, 99)", memberID, fromID, toID, by, moving.ID, from.ID, to.ID)
+	}
+}
+
+func TestInsertTeamMemberReplacesLead(t *testing.T) {
+	m := openTestModel(t)
+	ctx := context.Background()
+
+	p := &dto.Project{Title: "Led", Currency: "USD", Visibility: dto.VisibilityPublic, OwnerID: 7}
+	if err := m.CreateProjectTxContext(ctx, p, ""); err != nil {
+		t.Fatalf("CreateProjectTxContext: %v", err)
+	}
+	first := &dto.TeamMember{ProjectID: p.ID, Title: "First", IsLead: true}
+	second := &dto.TeamMember{ProjectID: p.ID, Title: "Second", IsLead: true}
+	for _, member := range []*dto.TeamMember{first, second} {
+		if err := m.InsertTeamMemberContext(ctx, member); err != nil {
+			t.Fatalf("InsertTeamMemberContext: %v", err)
+		}
+	}
+
+	for _, tt := range []struct {
+		member *dto.TeamMember
+		lead   bool
+	}{{first, false}, {second, true}} {
+		got, err := m.GetTeamMemberByIDContext(ctx, tt.member.ID)
+		if err != nil {
+			t.Fatalf("GetTeamMemberByIDContext: %v", err)
+		}
+		if got.IsLead != tt.lead {
+			t.Errorf("member %q IsLead = %v, want %v", got.Title, got.IsLead, tt.lead)
+		}
+	}
+}
+
+func TestCreateProjectTxRollsBackOnImageInsertFailure(t *testing.T) {
+	m := openTestModel(t)
+	ctx := context.Background()
+
+	// project_images.file_path is VARCHAR(255); a longer name fails the image insert,
+	// which runs after the pitch decks are inserted.
+	p := &dto.Project{
+		Title:      "Half saved",
+		Currency:   "USD",
+		Visibility: dto.VisibilityPublic,
+		OwnerID:    7,
+		PitchDecks: []string{"deck.pdf"},
+		Images:     []string{strings.Repeat("x", 300) + ".png"},
+	}
+	if err := m.CreateProjectTxContext(ctx, p, ""); err == nil {
+		t.Fatal("CreateProjectTxContext succeeded, want the image insert to fail")
+	}
+
+	count, _, err := m.ProjectListStatsContext(ctx, dto.ProjectFilter{OwnerID: p.OwnerID})
+	if err != nil {
+		t.Fatalf("ProjectListStatsContext: %v", err)
+	}
+	var decks int
+	if err := m.db.QueryRow(m.q(`SELECT COUNT(*) FROM project_pitch_decks`)).Scan(&decks); err != nil {
+		t.Fatalf("counting pitch decks: %v", err)
+	}
+	if count != 0 || decks != 0 {
+		t.Errorf("after a failed create: %d projects and %d pitch decks stored, want none", count, decks)
+	}
+}
+
+func TestSumValueByIndustryExcludesNonPublicProjects(t *testing.T) {
+	m := openTestModel(t)
+	ctx := context.Background()
+
+	for _, p := range []*dto.Project{
+		{Title: "Public", Industry: "Fintech", ProjectValue: 100, Currency: "USD", Visibility: dto.VisibilityPublic, OwnerID: 7},
+		{Title: "Private", Industry: "Fintech", ProjectValue: 5000, Currency: "USD", Visibility: dto.VisibilityPrivate, OwnerID: 7},
+		{Title: "Unlisted", Industry: "Secret", ProjectValue: 900, Currency: "USD", Visibility: dto.VisibilityUnlisted, OwnerID: 7},
+	} {
+		if err := m.CreateProjectTxContext(ctx, p, ""); err != nil {
+			t.Fatalf("CreateProjectTxContext: %v", err)
+		}
+	}
+
+	stats, err := m.SumValueByIndustryContext(ctx)
+	if err != nil {
+		t.Fatalf("SumValueByIndustryContext: %v", err)
+	}
+	want := []dto.IndustryValueStat{{Industry: "Fintech", Total: 100, Average: 100, Count: 1}}
+	if !reflect.DeepEqual(stats, want) {
+		t.Errorf("stats = %+v, want %+v", stats, want)
+	}
+}
+
+func TestSetProjectLikesTxSkipsNoOpsAndMissingProjects(t *testing.T) {
+	m := openTestModel(t)
+	ctx := context.Background()
+
+	liked := &dto.Project{Title: "Liked", Currency: "USD", Visibility: dto.VisibilityPublic, OwnerID: 7}
+	fresh := &dto.Project{Title: "Fresh", Currency: "USD", Visibility: dto.VisibilityPublic, OwnerID: 7}
+	for _, p := range []*dto.Project{liked, fresh} {
+		if err := m.CreateProjectTxContext(ctx, p, ""); err != nil {
+			t.Fatalf("CreateProjectTxContext: %v", err)
+		}
+	}
+	if _, err := m.SetProjectLikeTxContext(ctx, liked.ID, 9, true); err != nil {
+		t.Fatalf("SetProjectLikeTxContext: %v", err)
+	}
+
+	missing := fresh.ID + 1000
+	outcomes, err := m.SetProjectLikesTxContext(ctx, 9, map[int]bool{liked.ID: true, fresh.ID: true, missing: false})
+	if err != nil {
+		t.Fatalf("SetProjectLikesTxContext: %v", err)
+	}
+	want := map[int]dto.LikeOutcome{
+		liked.ID: {Changed: false, LikeCount: 1},
+		fresh.ID: {Changed: true, LikeCount: 1},
+	}
+	if !reflect.DeepEqual(outcomes, want) {
+		t.Errorf("outcomes = %+v, want %+v", outcomes, want)
+	}
+}
+
+func TestReconcileLikeCountsCorrectsDrift(t *testing.T) {
+	m := openTestModel(t)
+	ctx := context.Background()
+
+	p := &dto.Project{Title: "Drifted", Currency: "USD", Visibility: dto.VisibilityPublic, OwnerID: 7}
+	if err := m.CreateProjectTxContext(ctx, p, ""); err != nil {
+		t.Fatalf("CreateProjectTxContext: %v", err)
+	}
+	if _, err := m.SetProjectLikeTxContext(ctx, p.ID, 9, true); err != nil {
+		t.Fatalf("SetProjectLikeTxContext: %v", err)
+	}
+	if _, err := m.db.ExecContext(ctx, m.q(`UPDATE projects SET like_count = 5 WHERE id = ?`), p.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	corrected, err := m.ReconcileLikeCountsContext(ctx)
+	if err != nil {
+		t.Fatalf("ReconcileLikeCountsContext: %v", err)
+	}
+	if corrected != 1 {
+		t.Errorf("corrected = %d, want 1", corrected)
+	}
+	var count int
+	if err := m.db.QueryRowContext(ctx, m.q(`SELECT like_count FROM projects WHERE id = ?`), p.ID).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("like_count = %d, want 1", count)
+	}
+}
@@ -0,0 +1,160 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/tarsuniversecentral/project-module/pkg/tracing"
+)
+
+// maxLoggedQueryLength caps the statement text in a slow-query log line.
+const maxLoggedQueryLength = 300
+
+// slowQueryLogger logs statements that run longer than a threshold.
+type slowQueryLogger struct {
+	logger    *slog.Logger
+	threshold time.Duration
+}
+
+// newSlowQueryLogger returns a logger writing JSON lines to stdout, like the request log.
+// A zero threshold disables it.
+func newSlowQueryLogger(threshold time.Duration) *slowQueryLogger {
+	return &slowQueryLogger{
+		logger:    slog.New(slog.NewJSONHandler(os.Stdout, nil)),
+		threshold: threshold,
+	}
+}
+
+// querier is the part of *sql.DB and *sql.Tx the model runs statements through.
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// query runs db.QueryContext and logs the statement if it is slow. The time measured is
+// until the first rows are available, not until they are all read.
+func (m *ProjectModel) query(ctx context.Context, db querier, query string, args ...interface{}) (*sql.Rows, error) {
+	ctx, span := startQuerySpan(ctx, query)
+	defer span.End()
+
+	start := time.Now()
+	rows, err := db.QueryContext(ctx, query, args...)
+	m.slowQueries.observe(ctx, start, query, len(args))
+	span.RecordError(err)
+	return rows, err
+}
+
+// queryRow runs db.QueryRowContext and logs the statement if it is slow.
+func (m *ProjectModel) queryRow(ctx context.Context, db querier, query string, args ...interface{}) *sql.Row {
+	ctx, span := startQuerySpan(ctx, query)
+	defer span.End()
+
+	start := time.Now()
+	row := db.QueryRowContext(ctx, query, args...)
+	m.slowQueries.observe(ctx, start, query, len(args))
+	return row
+}
+
+// exec runs db.ExecContext and logs the statement if it is slow.
+func (m *ProjectModel) exec(ctx context.Context, db querier, query string, args ...interface{}) (sql.Result, error) {
+	ctx, span := startQuerySpan(ctx, query)
+	defer span.End()
+
+	start := time.Now()
+	result, err := db.ExecContext(ctx, query, args...)
+	m.slowQueries.observe(ctx, start, query, len(args))
+	span.RecordError(err)
+	return result, err
+}
+
+// queryStmt runs a prepared statement's QueryContext; query is its text, for the log.
+func (m *ProjectModel) queryStmt(ctx context.Context, stmt *sql.Stmt, query string, args ...interface{}) (*sql.Rows, error) {
+	ctx, span := startQuerySpan(ctx, query)
+	defer span.End()
+
+	start := time.Now()
+	rows, err := stmt.QueryContext(ctx, args...)
+	m.slowQueries.observe(ctx, start, query, len(args))
+	span.RecordError(err)
+	return rows, err
+}
+
+// queryRowStmt runs a prepared statement's QueryRowContext; query is its text, for the log.
+func (m *ProjectModel) queryRowStmt(ctx context.Context, stmt *sql.Stmt, query string, args ...interface{}) *sql.Row {
+	ctx, span := startQuerySpan(ctx, query)
+	defer span.End()
+
+	start := time.Now()
+	row := stmt.QueryRowContext(ctx, args...)
+	m.slowQueries.observe(ctx, start, query, len(args))
+	return row
+}
+
+// startQuerySpan starts the span of one statement, carrying its text but not its arguments,
+// which may be personal data.
+func startQuerySpan(ctx context.Context, query string) (context.Context, tracing.Span) {
+	ctx, span := tracing.Start(ctx, "db.query")
+	span.SetAttribute("db.statement", query)
+	return ctx, span
+}
+
+// observe logs the statement started at start if it took longer than the threshold. Only
+// the number of arguments is logged, as their values may be personal data.
+func (l *slowQueryLogger) observe(ctx context.Context, start time.Time, query string, args int) {
+	if l == nil || l.threshold <= 0 {
+		return
+	}
+	elapsed := time.Since(start)
+	if elapsed < l.threshold {
+		return
+	}
+
+	l.logger.LogAttrs(ctx, slog.LevelWarn, "slow query",
+		slog.String("method", callingMethod()),
+		slog.String("query", compactQuery(query)),
+		slog.Int("args", args),
+		slog.Float64("duration_ms", float64(elapsed.Microseconds())/1000),
+		slog.Float64("threshold_ms", float64(l.threshold.Microseconds())/1000),
+	)
+}
+
+// callingMethod returns the name of the innermost exported ProjectModel method on the
+// stack, such as "GetProjectFullDetailsContext", so statements run by shared helpers are
+// attributed to the call that needed them. It falls back to the innermost caller outside
+// this file.
+func callingMethod() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+
+	fallback := "unknown"
+	for {
+		frame, more := frames.Next()
+		if _, method, ok := strings.Cut(frame.Function, "(*ProjectModel)."); ok {
+			if r := []rune(method); len(r) > 0 && unicode.IsUpper(r[0]) && !strings.Contains(method, ".") {
+				return method
+			}
+		}
+		if fallback == "unknown" && frame.Function != "" && !strings.HasSuffix(frame.File, "querylog.go") {
+			fallback = frame.Function[strings.LastIndex(frame.Function, "/")+1:]
+		}
+		if !more {
+			return fallback
+		}
+	}
+}
+
+// compactQuery collapses the statement's whitespace onto one line and truncates it.
+func compactQuery(query string) string {
+	query = strings.Join(strings.Fields(query), " ")
+	if len(query) > maxLoggedQueryLength {
+		query = query[:maxLoggedQueryLength] + "..."
+	}
+	return query
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/tarsuniversecentral/project-module/internal/dto"
+)
+
+// BenchmarkStmtCache compares running the project-by-ID query through the statement cache
+// with running it unprepared, which the MySQL driver prepares and closes on every call
+// unless the DSN sets interpolateParams=true. "lookup" is the cache's own overhead. Run it
+// with TEST_MYSQL_DSN set:
+//
+//	go test ./internal/models -run '^$' -bench StmtCache
+func BenchmarkStmtCache(b *testing.B) {
+	m := openTestModel(b)
+	ctx := context.Background()
+
+	p := &dto.Project{Title: "Benchmarked", Currency: "USD", Visibility: dto.VisibilityPublic, OwnerID: 7}
+	if err := m.CreateProjectTxContext(ctx, p, ""); err != nil {
+		b.Fatalf("CreateProjectTxContext: %v", err)
+	}
+
+	query := m.q(getProjectByIDQuery)
+	drain := func(b *testing.B, rows *sql.Rows, err error) {
+		if err != nil {
+			b.Fatal(err)
+		}
+		for rows.Next() {
+		}
+		if err := rows.Close(); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.Run("cached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			stmt, err := m.stmts.get(m.reader(), query)
+			if err != nil {
+				b.Fatal(err)
+			}
+			rows, err := stmt.QueryContext(ctx, p.ID)
+			drain(b, rows, err)
+		}
+	})
+
+	b.Run("unprepared", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			rows, err := m.reader().QueryContext(ctx, query, p.ID)
+			drain(b, rows, err)
+		}
+	})
+
+	b.Run("lookup", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := m.stmts.get(m.reader(), query); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
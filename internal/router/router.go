@@ -2,10 +2,16 @@ package router
 
 import (
 	"fmt"
+	"log"
 	"net/http"
 
 	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/tarsuniversecentral/project-module/config"
 	"github.com/tarsuniversecentral/project-module/internal/api"
+	"github.com/tarsuniversecentral/project-module/internal/dto"
+	"github.com/tarsuniversecentral/project-module/internal/middleware"
+	"github.com/tarsuniversecentral/project-module/pkg/utils"
 )
 
 func Routers(router *mux.Router) http.Handler {
@@ -21,18 +27,192 @@ func welcome(w http.ResponseWriter, r *http.Request) {
 }
 
 // NewRouter registers routes for all domains and returns a configured router.
-func NewRouter(api *api.API) *mux.Router {
+func NewRouter(api *api.API, cfg *config.Config) *mux.Router {
 	router := mux.NewRouter().StrictSlash(true)
 
-	// Project routes.
-	projectRouter := router.PathPrefix("/projects").Subrouter()
-	projectRouter.HandleFunc("", api.ProjectHandler.CreateProject).Methods("POST")
-	projectRouter.HandleFunc("/{id:[0-9]+}", api.ProjectHandler.GetProject).Methods("GET")
-	projectRouter.HandleFunc("/file/{filename}", api.ProjectHandler.FileRetrieveHandler).Methods("GET")
+	// Unmatched routes get the same JSON error body as the handlers.
+	router.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		utils.WriteJSONError(w, http.StatusNotFound, "Not found", utils.ErrCodeNotFound)
+	})
+	router.MethodNotAllowedHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		utils.WriteJSONError(w, http.StatusMethodNotAllowed, "Method not allowed", utils.ErrCodeMethodNotAllowed)
+	})
+
+	// Tag every request with an ID for log correlation, then log it, including
+	// requests rejected by the middleware below.
+	router.Use(middleware.RequestID)
+	router.Use(middleware.RequestLogger())
+
+	// Start a span per request, the root of the service and database spans below it. A
+	// no-op until a tracer is installed with tracing.SetTracer.
+	router.Use(middleware.Tracing)
+
+	// Record request metrics, including requests shed or rejected below.
+	router.Use(middleware.NewMetrics(api.MetricsHandler.Registry()).Middleware)
+
+	// Answer CORS preflights before anything can reject or redirect them.
+	if len(cfg.AllowedOrigins) > 0 {
+		router.Use(middleware.CORS(cfg.AllowedOrigins))
+	}
+
+	// Shed load before doing any other work. Health checks and metrics stay reachable under load.
+	if cfg.MaxInFlightRequests > 0 {
+		limiter := middleware.NewInFlightLimiter(cfg.MaxInFlightRequests, cfg.RetryAfter.Overloaded, "/healthz", "/readyz", "/metrics")
+		router.Use(limiter.Middleware)
+		api.MetricsHandler.Registry().MustRegister(
+			prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+				Name: "http_inflight_limit_current",
+				Help: "Requests currently counted against the in-flight limit.",
+			}, func() float64 {
+				return float64(limiter.InFlight())
+			}),
+			prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+				Name: "http_inflight_limit_max",
+				Help: "Maximum number of requests handled concurrently before shedding load.",
+			}, func() float64 {
+				return float64(limiter.Limit())
+			}),
+		)
+	}
+
+	// Resolve the client IP once for quota and logging purposes.
+	router.Use(middleware.ClientIPMiddleware(cfg.TrustProxyHeaders, cfg.TrustedProxies))
+
+	// Throttle each client IP. Health checks and metrics scrapes are never throttled.
+	if cfg.RateLimitRPS > 0 {
+		limiter := middleware.NewRateLimiter(cfg.RateLimitRPS, cfg.RateLimitBurst, cfg.RateLimitIdleTTL, cfg.RetryAfter.RateLimited, "/healthz", "/readyz", "/metrics")
+		router.Use(limiter.Middleware)
+	}
+
+	// Redirect to HTTPS behind a TLS-terminating proxy, off by default for local development.
+	if cfg.ForceHTTPS {
+		router.Use(middleware.EnforceHTTPS(cfg.HSTSMaxAge))
+	}
+
+	// Debug-only body logging, off by default.
+	if cfg.DebugBodyLogging {
+		log.Println("WARNING: request/response body logging is enabled")
+		router.Use(middleware.DebugBodyLogger(middleware.DebugBodyOptions{
+			MaxBytes:      cfg.DebugBodyLogMaxBytes,
+			RedactHeaders: cfg.DebugBodyLogRedactHeaders,
+			RedactFields:  cfg.DebugBodyLogRedactFields,
+		}))
+	}
+
+	// Health probes.
+	router.HandleFunc("/healthz", api.HealthHandler.Healthz).Methods("GET")
+	router.HandleFunc("/readyz", api.HealthHandler.Readyz).Methods("GET")
+
+	// Prometheus scrape endpoint, unauthenticated like the probes.
+	router.HandleFunc("/metrics", api.MetricsHandler.Metrics).Methods("GET")
+
+	// JSON responses are gzipped for clients that accept it. File downloads and event
+	// streams are left alone: they are binary or already compressed, or must not be buffered.
+	compress := func(next http.Handler) http.Handler { return next }
+	if cfg.CompressionMinBytes > 0 {
+		compress = middleware.Compress(cfg.CompressionMinBytes)
+	}
 
-	projectRouter.HandleFunc("/{projectId:[0-9]+}/teammember", api.ProjectHandler.AddTeamMemberToProject).Methods("POST")
-	projectRouter.HandleFunc("/{projectId:[0-9]+}/teammembers", api.ProjectHandler.GetTeamMembersOfProject).Methods("GET")
-	projectRouter.HandleFunc("/teammember/role/{memberId}", api.ProjectHandler.UpdateTeamMemberRole).Methods("PUT")
+	// API contract: the OpenAPI spec in internal/handlers/openapi.json and a Swagger UI page.
+	router.Handle("/openapi.json", compress(http.HandlerFunc(api.DocsHandler.OpenAPI))).Methods("GET")
+	router.HandleFunc("/docs", api.DocsHandler.SwaggerUI).Methods("GET")
+
+	// Write routes require an authenticated user; reads stay public, but identify the user
+	// when there is one so owners can read their private projects.
+	var requireUser, optionalUser func(http.Handler) http.Handler
+	if cfg.JWTSecret != "" {
+		requireUser = middleware.JWTAuth([]byte(cfg.JWTSecret), cfg.JWTIssuer, true)
+		optionalUser = middleware.JWTAuth([]byte(cfg.JWTSecret), cfg.JWTIssuer, false)
+	} else {
+		// Config validation only allows this with ALLOW_INSECURE_USER_HEADER.
+		log.Println("WARNING: ALLOW_INSECURE_USER_HEADER is set; routes trust the unauthenticated X-User-ID header")
+		requireUser = middleware.UserContextMiddleware(true)
+		optionalUser = middleware.UserContextMiddleware(false)
+	}
+
+	// Versioned API routes live under /api/v1, e.g. GET /api/v1/projects.
+	registerAPIRoutes(router, dto.APIBasePath, api, cfg, requireUser, optionalUser, compress)
+
+	// The same routes without the prefix, kept for existing clients during the deprecation
+	// window. Responses point them at the versioned path.
+	if cfg.UnversionedRoutes {
+		registerAPIRoutes(router, "", api, cfg, requireUser, optionalUser, compress, middleware.Deprecated(dto.APIBasePath))
+	}
+
+	// Catch-all OPTIONS route. Routes are registered per method, so without it a preflight
+	// would be a method mismatch and the router middleware (including CORS) would not run.
+	if len(cfg.AllowedOrigins) > 0 {
+		router.Methods(http.MethodOptions).HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
 
 	return router
 }
+
+// registerAPIRoutes registers the project and admin routes under basePath, with the given
+// middleware in front of them. Paths below are relative to it: "/projects" is served as
+// /api/v1/projects and, while unversioned routes are enabled, as /projects. optionalUser
+// fronts the reads that show owners more than other callers. compress wraps the routes
+// answering with JSON.
+func registerAPIRoutes(router *mux.Router, basePath string, api *api.API, cfg *config.Config, requireUser, optionalUser, compress func(http.Handler) http.Handler, mws ...mux.MiddlewareFunc) {
+	// Project routes.
+	projectRouter := router.PathPrefix(basePath + "/projects").Subrouter()
+	projectRouter.Use(mws...)
+	projectRouter.Use(compress)
+
+	projectWrites := projectRouter.Methods("POST", "PUT", "PATCH", "DELETE").Subrouter()
+	projectWrites.Use(requireUser)
+	projectWrites.HandleFunc("", api.ProjectHandler.CreateProject).Methods("POST")
+	projectWrites.HandleFunc("/import/github", api.ProjectHandler.ImportGithubProject).Methods("POST")
+	projectWrites.HandleFunc("/{id:[0-9]+}", api.ProjectHandler.UpdateProject).Methods("PATCH")
+	projectWrites.HandleFunc("/{id:[0-9]+}", api.ProjectHandler.DeleteProject).Methods("DELETE")
+	projectWrites.HandleFunc("/{id:[0-9]+}/images", api.ProjectHandler.DeleteProjectImages).Methods("DELETE")
+	projectWrites.HandleFunc("/{id:[0-9]+}/images/{filename}/primary", api.ProjectHandler.SetPrimaryImage).Methods("PUT")
+	projectWrites.HandleFunc("/{id:[0-9]+}/pdfs", api.ProjectHandler.DeleteProjectPitchDecks).Methods("DELETE")
+	projectWrites.HandleFunc("/{id:[0-9]+}/files/{filename}", api.ProjectHandler.DeleteProjectFile).Methods("DELETE")
+	projectWrites.HandleFunc("/file/{filename}", api.ProjectHandler.DeleteFile).Methods("DELETE")
+	projectWrites.HandleFunc("/{projectId:[0-9]+}/teammember", api.ProjectHandler.AddTeamMemberToProject).Methods("POST")
+	projectWrites.HandleFunc("/teammember/role/{memberId}", api.ProjectHandler.UpdateTeamMemberRole).Methods("PUT")
+	projectWrites.HandleFunc("/{projectId:[0-9]+}/teammember/{memberId:[0-9]+}/lead", api.ProjectHandler.SetProjectLead).Methods("PUT")
+	projectWrites.HandleFunc("/teammember/{memberId:[0-9]+}", api.ProjectHandler.DeleteTeamMember).Methods("DELETE")
+	projectWrites.HandleFunc("/{projectId:[0-9]+}/like", api.ProjectHandler.LikeProject).Methods("POST")
+	projectWrites.HandleFunc("/{projectId:[0-9]+}/like", api.ProjectHandler.UnlikeProject).Methods("DELETE")
+	projectWrites.HandleFunc("/{id:[0-9]+}/preview-token", api.ProjectHandler.CreatePreviewToken).Methods("POST")
+	projectWrites.HandleFunc("/{id:[0-9]+}/preview-token", api.ProjectHandler.RevokePreviewTokens).Methods("DELETE")
+
+	projectRouter.HandleFunc("", api.ProjectHandler.ListProjects).Methods("GET")
+	projectRouter.HandleFunc("/count", api.ProjectHandler.CountProjects).Methods("GET")
+	projectRouter.Handle("/mine", requireUser(http.HandlerFunc(api.ProjectHandler.ListMyProjects))).Methods("GET")
+	projectRouter.HandleFunc("/industries", api.ProjectHandler.ListIndustries).Methods("GET")
+	projectRouter.HandleFunc("/stats/value-by-industry", api.ProjectHandler.GetValueByIndustry).Methods("GET")
+	projectRouter.Handle("/{id:[0-9]+}", optionalUser(http.HandlerFunc(api.ProjectHandler.GetProject))).Methods("GET")
+	projectRouter.Handle("/{projectId:[0-9]+}/teammembers", optionalUser(http.HandlerFunc(api.ProjectHandler.GetTeamMembersOfProject))).Methods("GET")
+	projectRouter.Handle("/teammember/{memberId:[0-9]+}", optionalUser(http.HandlerFunc(api.ProjectHandler.GetTeamMember))).Methods("GET")
+
+	// Batched likes, for clients syncing likes made offline.
+	likeRouter := router.PathPrefix(basePath + "/likes").Subrouter()
+	likeRouter.Use(mws...)
+	likeRouter.Use(compress)
+	likeRouter.Use(requireUser)
+	likeRouter.HandleFunc("/batch", api.ProjectHandler.BatchLikes).Methods("POST")
+
+	// File downloads and event streams share the prefix but skip compression.
+	streamRouter := router.PathPrefix(basePath + "/projects").Subrouter()
+	streamRouter.Use(mws...)
+	streamRouter.Handle("/file/{filename}", optionalUser(http.HandlerFunc(api.ProjectHandler.FileRetrieveHandler))).Methods("GET")
+
+	// Live project events over Server-Sent Events, when enabled.
+	if cfg.EventMaxSubscribers > 0 {
+		streamRouter.Handle("/{id:[0-9]+}/events", optionalUser(http.HandlerFunc(api.ProjectHandler.StreamProjectEvents))).Methods("GET")
+	}
+
+	// Admin routes, for users holding the admin role.
+	adminRouter := router.PathPrefix(basePath + "/admin").Subrouter()
+	adminRouter.Use(mws...)
+	adminRouter.Use(compress)
+	adminRouter.Use(requireUser)
+	adminRouter.Use(middleware.RequireRole(middleware.RoleAdmin))
+	adminRouter.HandleFunc("/teammembers/reassign", api.ProjectHandler.ReassignTeamMembers).Methods("POST")
+	adminRouter.HandleFunc("/counts/reconcile", api.ProjectHandler.ReconcileCounts).Methods("POST")
+}
@@ -0,0 +1,36 @@
+package router
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tarsuniversecentral/project-module/config"
+	"github.com/tarsuniversecentral/project-module/internal/api"
+	"github.com/tarsuniversecentral/project-module/internal/handlers"
+)
+
+func TestInFlightLimitMetrics(t *testing.T) {
+	// sql.Open doesn't connect; the metrics handler only reads pool stats.
+	db, err := sql.Open("mysql", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	a := &api.API{MetricsHandler: handlers.NewMetricsHandler(db)}
+	router := NewRouter(a, &config.Config{MaxInFlightRequests: 3, JWTSecret: "test"})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /metrics = %d", w.Code)
+	}
+	for _, want := range []string{"http_inflight_limit_max 3", "http_inflight_limit_current 0"} {
+		if !strings.Contains(w.Body.String(), want) {
+			t.Errorf("metrics don't include %q", want)
+		}
+	}
+}
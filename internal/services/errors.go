@@ -0,0 +1,71 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrValidation marks errors caused by invalid client input. Handlers map it to 400 Bad Request.
+	ErrValidation = errors.New("validation failed")
+	// ErrProjectNotFound is returned when the requested project doesn't exist. Handlers map it to 404.
+	ErrProjectNotFound = errors.New("project not found")
+	// ErrTeamMemberNotFound is returned when the requested team member doesn't exist. Handlers map it to 404.
+	ErrTeamMemberNotFound = errors.New("team member not found")
+	// ErrForbidden is returned when the caller may see a project but not change it, because
+	// they don't own it. Handlers map it to 403.
+	ErrForbidden = errors.New("forbidden")
+	// ErrFileNotFound is returned when the requested file doesn't exist or the caller may not see it. Handlers map it to 404.
+	ErrFileNotFound = errors.New("file not found")
+	// ErrFileInUse is returned when deleting a stored file that a project still references. Handlers map it to 409.
+	ErrFileInUse = errors.New("file in use")
+	// ErrVersionConflict is returned when an update names a version of a record that someone
+	// else has since changed. Handlers map it to 409.
+	ErrVersionConflict = errors.New("version conflict")
+
+	// ErrFileTooLarge is returned when an upload exceeds a size limit. Handlers map it to 413.
+	ErrFileTooLarge = errors.New("file too large")
+	// ErrInvalidFileType is returned when an upload's extension or content isn't accepted. Handlers map it to 415.
+	ErrInvalidFileType = errors.New("invalid file type")
+	// ErrStorageFull is returned when an upload can't be stored for lack of space. Handlers map it to 507.
+	ErrStorageFull = errors.New("storage full")
+)
+
+// projectNotFound returns ErrProjectNotFound for the project with the given ID.
+func projectNotFound(id int) error {
+	return fmt.Errorf("%w: project with ID %d does not exist", ErrProjectNotFound, id)
+}
+
+// teamMemberNotFound returns ErrTeamMemberNotFound for the team member with the given ID.
+func teamMemberNotFound(id int) error {
+	return fmt.Errorf("%w: team member with ID %d does not exist", ErrTeamMemberNotFound, id)
+}
+
+// uploadError aggregates the failures of a batch of uploads. Its message joins them all,
+// and errors.Is matches any sentinel one of them wraps.
+type uploadError struct {
+	errs []error
+}
+
+func (e *uploadError) Error() string {
+	messages := make([]string, 0, len(e.errs))
+	for _, err := range e.errs {
+		messages = append(messages, err.Error())
+	}
+	return fmt.Sprintf("errors occurred while saving files: %s", strings.Join(messages, "; "))
+}
+
+func (e *uploadError) Unwrap() []error {
+	return e.errs
+}
+
+// FileDeletionError reports the stored files that could not be removed.
+type FileDeletionError struct {
+	Files    []string
+	Messages []string
+}
+
+func (e *FileDeletionError) Error() string {
+	return fmt.Sprintf("errors occurred while deleting files: %s", strings.Join(e.Messages, "; "))
+}
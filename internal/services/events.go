@@ -0,0 +1,104 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/tarsuniversecentral/project-module/internal/dto"
+)
+
+// subscriberBuffer is how many events may queue for a slow subscriber before new
+// events are dropped for it. Status events are full snapshots, so a dropped event
+// is superseded by the next one.
+const subscriberBuffer = 16
+
+// SubscriberLimitError is returned when the event subscriber cap is reached.
+type SubscriberLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *SubscriberLimitError) Error() string {
+	return fmt.Sprintf("too many event subscribers, retry after %s", e.RetryAfter.Round(time.Second))
+}
+
+// EventBroker is an in-memory pub/sub of project events. It only reaches subscribers
+// connected to this process.
+type EventBroker struct {
+	maxSubscribers int
+	retryAfter     time.Duration
+
+	mu          sync.Mutex
+	subscribers map[int]map[*EventSubscription]struct{}
+	count       int
+}
+
+// EventSubscription receives the events of one project until it is closed.
+type EventSubscription struct {
+	Events <-chan dto.ProjectEvent
+
+	events    chan dto.ProjectEvent
+	projectID int
+	broker    *EventBroker
+	once      sync.Once
+}
+
+// NewEventBroker returns a broker allowing at most maxSubscribers concurrent subscriptions.
+// retryAfter is advertised to subscribers turned away when it is full.
+func NewEventBroker(maxSubscribers int, retryAfter time.Duration) *EventBroker {
+	return &EventBroker{
+		maxSubscribers: maxSubscribers,
+		retryAfter:     retryAfter,
+		subscribers:    make(map[int]map[*EventSubscription]struct{}),
+	}
+}
+
+// Subscribe registers a subscription to the project's events, or returns a
+// *SubscriberLimitError when the broker is full.
+func (b *EventBroker) Subscribe(projectID int) (*EventSubscription, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.count >= b.maxSubscribers {
+		return nil, &SubscriberLimitError{RetryAfter: b.retryAfter}
+	}
+
+	events := make(chan dto.ProjectEvent, subscriberBuffer)
+	sub := &EventSubscription{Events: events, events: events, projectID: projectID, broker: b}
+	if b.subscribers[projectID] == nil {
+		b.subscribers[projectID] = make(map[*EventSubscription]struct{})
+	}
+	b.subscribers[projectID][sub] = struct{}{}
+	b.count++
+	return sub, nil
+}
+
+// Publish delivers the event to the project's subscribers without blocking.
+func (b *EventBroker) Publish(event dto.ProjectEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for sub := range b.subscribers[event.ProjectID] {
+		select {
+		case sub.events <- event:
+		default:
+			log.Printf("Dropping %s event for a slow subscriber of project %d", event.Type, event.ProjectID)
+		}
+	}
+}
+
+// Close unregisters the subscription. It is safe to call more than once.
+func (s *EventSubscription) Close() {
+	s.once.Do(func() {
+		b := s.broker
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		delete(b.subscribers[s.projectID], s)
+		if len(b.subscribers[s.projectID]) == 0 {
+			delete(b.subscribers, s.projectID)
+		}
+		b.count--
+	})
+}
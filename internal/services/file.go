@@ -1,58 +1,169 @@
 package services
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"mime/multipart"
+	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
+	"unicode/utf8"
 
+	"github.com/google/uuid"
+
+	"github.com/tarsuniversecentral/project-module/config"
 	"github.com/tarsuniversecentral/project-module/internal/dto"
+	"github.com/tarsuniversecentral/project-module/pkg/storage"
 	"github.com/tarsuniversecentral/project-module/pkg/utils"
 )
 
 type FileService struct {
+	storage storage.Storage
+	quota   *UploadQuota
+
+	// CDN offload; files are served directly when cdnBaseURL is empty.
+	cdnBaseURL      string
+	cdnVisibilities map[dto.Visibility]bool
+
+	// Upload limits; zero disables each one.
+	maxFileBytes    int64
+	maxFiles        int
+	maxRequestBytes int64
+
+	// concurrency caps how many files one request saves or deletes at once.
+	concurrency int
+
+	// previews renders pitch deck previews; nil disables them.
+	previews *PreviewGenerator
+}
+
+func NewFileService(cfg *config.Config, store storage.Storage) *FileService {
+	fs := &FileService{
+		storage:         store,
+		maxFileBytes:    cfg.MaxUploadBytes,
+		maxFiles:        cfg.MaxUploadFiles,
+		maxRequestBytes: cfg.MaxUploadRequestBytes,
+		concurrency:     max(cfg.UploadConcurrency, 1),
+		cdnBaseURL:      cfg.CDNBaseURL,
+		cdnVisibilities: make(map[dto.Visibility]bool),
+		previews:        NewPreviewGenerator(cfg.PDFPreviewCommand, cfg.PDFPreviewTimeout),
+	}
+	for _, v := range cfg.CDNVisibilities {
+		fs.cdnVisibilities[dto.Visibility(v)] = true
+	}
+	if cfg.UploadQuotaBytes > 0 {
+		fs.quota = NewUploadQuota(cfg.UploadQuotaBytes, cfg.UploadQuotaWindow)
+	}
+	return fs
+}
+
+// CheckUploadQuota reports whether the client may upload n more bytes, and if not,
+// how long it should wait. It always allows uploads when no quota is configured.
+func (fs *FileService) CheckUploadQuota(client string, n int64) (bool, time.Duration) {
+	if fs.quota == nil {
+		return true, 0
+	}
+	return fs.quota.Check(client, n)
 }
 
-func NewFileService() *FileService {
-	return &FileService{}
+// RecordUpload accounts n successfully saved bytes against the client's quota.
+func (fs *FileService) RecordUpload(client string, n int64) {
+	if fs.quota != nil {
+		fs.quota.Record(client, n)
+	}
+}
+
+// formFieldAllowance is the room left for non-file form fields and multipart
+// framing when capping the request body.
+const formFieldAllowance = 1 << 20
+
+// MaxRequestBodyBytes returns the largest upload request body worth reading, or zero
+// when the combined upload size is not limited. Handlers use it to stop reading
+// oversized bodies before they are parsed.
+func (fs *FileService) MaxRequestBodyBytes() int64 {
+	if fs.maxRequestBytes <= 0 {
+		return 0
+	}
+	return fs.maxRequestBytes + formFieldAllowance
+}
+
+// checkUploadLimits rejects the upload before anything is saved if it has too many
+// files, a file over the per-file limit, or too many bytes in total.
+func (fs *FileService) checkUploadLimits(headerSets ...[]*multipart.FileHeader) error {
+	var (
+		count int
+		total int64
+	)
+	for _, headers := range headerSets {
+		for _, header := range headers {
+			if fs.maxFileBytes > 0 && header.Size > fs.maxFileBytes {
+				return fmt.Errorf("%w: file %s is %d bytes, over the %d byte limit", ErrFileTooLarge, header.Filename, header.Size, fs.maxFileBytes)
+			}
+			count++
+			total += header.Size
+		}
+	}
+
+	if fs.maxFiles > 0 && count > fs.maxFiles {
+		return fmt.Errorf("%w: %d files uploaded, at most %d are allowed per request", ErrValidation, count, fs.maxFiles)
+	}
+	if fs.maxRequestBytes > 0 && total > fs.maxRequestBytes {
+		return fmt.Errorf("%w: uploaded files total %d bytes, over the %d byte limit per request", ErrFileTooLarge, total, fs.maxRequestBytes)
+	}
+	return nil
 }
 
 // ProcessUploads saves the uploaded PDF and image files concurrently.
-// If any error occurs, it deletes all the files that were saved.
-const maxConcurrents = 10
+// If any error occurs, it deletes all the files that were saved. Cancelling ctx, e.g. on
+// shutdown, stops the saves in progress and counts as an error, so nothing is left behind.
+func (fs *FileService) ProcessUploads(ctx context.Context, pdfHeaders, imageHeaders []*multipart.FileHeader) (dto.SavedFiles, error) {
+	if err := fs.checkUploadLimits(pdfHeaders, imageHeaders); err != nil {
+		return dto.SavedFiles{}, err
+	}
 
-func (fs *FileService) ProcessUploads(pdfHeaders, imageHeaders []*multipart.FileHeader) (dto.SavedFiles, error) {
 	totalFiles := len(pdfHeaders) + len(imageHeaders)
-	resultsCh := make(chan dto.FileResult, totalFiles)
+	// Each PDF may also produce a preview image.
+	resultsCh := make(chan dto.FileResult, totalFiles+len(pdfHeaders))
 	errCh := make(chan error, totalFiles)
 
 	var wg sync.WaitGroup
-	sem := make(chan struct{}, maxConcurrents) // Semaphore for limiting concurrency
+	sem := make(chan struct{}, fs.concurrency) // Semaphore for limiting concurrency
 
-	// Helper function to save a file.
-	saveFileConcurrently := func(header *multipart.FileHeader, fileType, destDir string) {
+	// Helper function to save a file. The file type is also the directory it's saved to,
+	// so results can be deleted by type if a later step fails.
+	saveFileConcurrently := func(header *multipart.FileHeader, fileType string) {
 		defer wg.Done()
 		defer func() { <-sem }()
 
+		// Don't start writing once the client is gone or the server is shutting down.
+		if err := ctx.Err(); err != nil {
+			errCh <- fmt.Errorf("upload of %s file %s cancelled: %w", fileType, header.Filename, err)
+			return
+		}
+
 		var allowedTypes []string
-		if fileType == "pdf" {
+		if fileType == dto.FileTypePDFs {
 			allowedTypes = []string{".pdf"}
-		} else if fileType == "images" {
-			allowedTypes = []string{".jpg", ".jpeg", ".png", ".svg"}
+		} else if fileType == dto.FileTypeImages {
+			allowedTypes = []string{".jpg", ".jpeg", ".png", ".svg", ".webp", ".gif"}
 		}
 
 		if !validateFileType(header, allowedTypes) {
-			errCh <- fmt.Errorf("invalid file type for %s: %s", fileType, header.Filename)
+			errCh <- fmt.Errorf("%w for %s: %s", ErrInvalidFileType, fileType, header.Filename)
 			return
 		}
 
 		log.Printf("Saving %s file: %s", fileType, header.Filename)
 
-		uniqueName, err := saveFile(header, destDir)
+		uniqueName, err := fs.saveFile(ctx, header, fileType)
 		if err != nil {
 			errCh <- fmt.Errorf("error saving %s file %s: %w", fileType, header.Filename, err)
 			return
@@ -60,21 +171,31 @@ func (fs *FileService) ProcessUploads(pdfHeaders, imageHeaders []*multipart.File
 
 		log.Printf("Saved %s file: %s", fileType, uniqueName)
 
-		resultsCh <- dto.FileResult{FileType: fileType, Filename: uniqueName}
+		resultsCh <- dto.FileResult{FileType: fileType, Filename: uniqueName, OriginalName: originalFilename(header.Filename)}
+
+		if fileType == dto.FileTypePDFs && fs.previews != nil {
+			preview, err := fs.savePreview(ctx, header, uniqueName)
+			if err != nil {
+				// Previews are best-effort; the deck is still usable without one.
+				log.Printf("Skipping preview for %s: %v", uniqueName, err)
+				return
+			}
+			resultsCh <- dto.FileResult{FileType: dto.FileTypeImages, Filename: preview, PreviewOf: uniqueName}
+		}
 	}
 
 	// Process PDF files concurrently.
 	for _, header := range pdfHeaders {
 		wg.Add(1)
 		sem <- struct{}{} // Acquire semaphore
-		go saveFileConcurrently(header, "pdf", "pdfs")
+		go saveFileConcurrently(header, dto.FileTypePDFs)
 	}
 
 	// Process image files concurrently.
 	for _, header := range imageHeaders {
 		wg.Add(1)
 		sem <- struct{}{} // Acquire semaphore
-		go saveFileConcurrently(header, "images", "images")
+		go saveFileConcurrently(header, dto.FileTypeImages)
 	}
 
 	wg.Wait()
@@ -100,20 +221,30 @@ func (fs *FileService) ProcessUploads(pdfHeaders, imageHeaders []*multipart.File
 			return dto.SavedFiles{}, fmt.Errorf("errors occurred while saving files: %v; errors occurred while deleting files: %v", errorsFound, err)
 		}
 
-		// Aggregate all errors into a single error message
-		var errorMessages []string
-		for _, err := range errorsFound {
-			errorMessages = append(errorMessages, err.Error())
-		}
-		return dto.SavedFiles{}, fmt.Errorf("errors occurred while saving files: %v", strings.Join(errorMessages, "; "))
+		// Aggregate all errors, keeping their sentinels visible to the handler.
+		return dto.SavedFiles{}, &uploadError{errs: errorsFound}
 	}
 
 	// Organize the results into the response struct.
 	var response dto.SavedFiles
 	for _, res := range savedFiles {
-		if res.FileType == "pdf" {
+		if res.PreviewOf != "" {
+			if response.PDFPreviews == nil {
+				response.PDFPreviews = make(map[string]string)
+			}
+			response.PDFPreviews[res.PreviewOf] = res.Filename
+			continue
+		}
+
+		if res.OriginalName != "" {
+			if response.OriginalNames == nil {
+				response.OriginalNames = make(map[string]string)
+			}
+			response.OriginalNames[res.Filename] = res.OriginalName
+		}
+		if res.FileType == dto.FileTypePDFs {
 			response.PDFFiles = append(response.PDFFiles, res.Filename)
-		} else if res.FileType == "images" {
+		} else if res.FileType == dto.FileTypeImages {
 			response.ImageFiles = append(response.ImageFiles, res.Filename)
 		}
 	}
@@ -122,8 +253,13 @@ func (fs *FileService) ProcessUploads(pdfHeaders, imageHeaders []*multipart.File
 }
 
 func (fs *FileService) DeleteSavedFiles(savedFiles []dto.FileResult) error {
-	sem := make(chan struct{}, maxConcurrents)
-	errorCh := make(chan string, len(savedFiles)) // Buffered channel for error messages.
+	type deleteFailure struct {
+		path    string
+		message string
+	}
+
+	sem := make(chan struct{}, fs.concurrency)
+	errorCh := make(chan deleteFailure, len(savedFiles)) // Buffered channel for failures.
 
 	var delWg sync.WaitGroup
 
@@ -137,10 +273,11 @@ func (fs *FileService) DeleteSavedFiles(savedFiles []dto.FileResult) error {
 				delWg.Done()
 			}()
 
-			path := filepath.Join(r.FileType, r.Filename)
-			if err := os.Remove(path); err != nil {
+			name := utils.SanitizeFilename(r.Filename)
+			path := filepath.Join(r.FileType, name)
+			if err := fs.storage.Delete(r.FileType, name); err != nil {
 				log.Printf("Error deleting file %s: %v", path, err)
-				errorCh <- fmt.Sprintf("deleting file %s: %v", path, err)
+				errorCh <- deleteFailure{path: path, message: fmt.Sprintf("deleting file %s: %v", path, err)}
 			}
 		}(res)
 	}
@@ -151,14 +288,15 @@ func (fs *FileService) DeleteSavedFiles(savedFiles []dto.FileResult) error {
 		close(errorCh)
 	}()
 
-	// Collect error messages.
-	var errorMessages []string
-	for msg := range errorCh {
-		errorMessages = append(errorMessages, msg)
+	// Collect failures.
+	var delErr FileDeletionError
+	for failure := range errorCh {
+		delErr.Files = append(delErr.Files, failure.path)
+		delErr.Messages = append(delErr.Messages, failure.message)
 	}
 
-	if len(errorMessages) > 0 {
-		return fmt.Errorf("errors occurred while deleting files: %s", strings.Join(errorMessages, "; "))
+	if len(delErr.Files) > 0 {
+		return &delErr
 	}
 
 	return nil
@@ -174,74 +312,218 @@ func validateFileType(header *multipart.FileHeader, allowedTypes []string) bool
 	return false
 }
 
-// saveFile saves an individual file to the destination directory.
-// It opens the uploaded file, creates a new file with a unique filename, and copies the content.
-func saveFile(header *multipart.FileHeader, destDir string) (string, error) {
+// sniffLen is the number of leading bytes inspected to identify a file's content.
+const sniffLen = 512
+
+// validateFileContent checks that the file's leading bytes match the type its extension
+// claims, so a renamed executable can't be stored as a PDF or image. The file is rewound
+// afterwards so it can still be copied in full.
+func validateFileContent(file multipart.File, filename string) error {
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return fmt.Errorf("reading %s: %w", filename, err)
+	}
+	head = head[:n]
 
-	if err := createDirIfNotExist(destDir); err != nil {
-		return "", fmt.Errorf("creating directory %s: %w", destDir, err)
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("rewinding %s: %w", filename, err)
 	}
 
+	ext := strings.ToLower(filepath.Ext(filename))
+	detected := http.DetectContentType(head)
+
+	var ok bool
+	switch ext {
+	case ".pdf":
+		ok = bytes.HasPrefix(head, []byte("%PDF-"))
+	case ".jpg", ".jpeg":
+		ok = detected == "image/jpeg"
+	case ".png":
+		ok = detected == "image/png"
+	case ".webp":
+		// RIFF container with a WEBP form type.
+		ok = detected == "image/webp"
+	case ".gif":
+		// GIF87a or GIF89a, animated or not.
+		ok = detected == "image/gif"
+	case ".svg":
+		// SVG is XML text, which DetectContentType can't tell apart from other XML.
+		ok = strings.HasPrefix(detected, "text/") && bytes.Contains(bytes.ToLower(head), []byte("<svg"))
+	}
+
+	if !ok {
+		return fmt.Errorf("%w: content of %s does not match its %s extension (detected %s)", ErrInvalidFileType, filename, ext, detected)
+	}
+	return nil
+}
+
+// saveFile saves an individual file to the destination directory.
+// It opens the uploaded file, checks its content, and stores it under a unique filename.
+func (fs *FileService) saveFile(ctx context.Context, header *multipart.FileHeader, destDir string) (string, error) {
+
 	file, err := header.Open()
 	if err != nil {
 		return "", fmt.Errorf("opening file: %w", err)
 	}
 	defer file.Close()
 
+	if err := validateFileContent(file, header.Filename); err != nil {
+		return "", err
+	}
+
 	uniqueName := utils.GenerateUniqueFilename(header.Filename)
-	dstPath := filepath.Join(destDir, uniqueName)
+	if err := fs.storage.Save(ctx, destDir, uniqueName, file); err != nil {
+		if errors.Is(err, storage.ErrNoSpace) {
+			return "", fmt.Errorf("%w: %v", ErrStorageFull, err)
+		}
+		return "", err
+	}
+	return uniqueName, nil
+}
+
+// maxOriginalNameLength matches the original_name columns.
+const maxOriginalNameLength = 255
 
-	dst, err := os.Create(dstPath)
+// originalFilename returns the name a file was uploaded under, made safe to store and to
+// send back in a Content-Disposition header. Long names are shortened, keeping the extension.
+func originalFilename(name string) string {
+	name = utils.SanitizeFilename(name)
+	if len(name) <= maxOriginalNameLength {
+		return name
+	}
+
+	ext := filepath.Ext(name)
+	if len(ext) >= maxOriginalNameLength {
+		ext = ""
+	}
+	stem := name[:maxOriginalNameLength-len(ext)]
+	// Don't leave half of a multi-byte character behind.
+	for !utf8.ValidString(stem) {
+		stem = stem[:len(stem)-1]
+	}
+	return stem + ext
+}
+
+// savePreview renders the first page of an uploaded pitch deck and stores it with the
+// images as "<deck>-preview.png". It returns the preview's filename.
+func (fs *FileService) savePreview(ctx context.Context, header *multipart.FileHeader, deckName string) (string, error) {
+	file, err := header.Open()
 	if err != nil {
-		return "", fmt.Errorf("creating destination file: %w", err)
+		return "", fmt.Errorf("opening file: %w", err)
+	}
+	defer file.Close()
+
+	png, err := fs.previews.Generate(ctx, file)
+	if err != nil {
+		return "", err
 	}
-	defer dst.Close()
 
-	if _, err := io.Copy(dst, file); err != nil {
-		return "", fmt.Errorf("copying file: %w", err)
+	name := strings.TrimSuffix(deckName, filepath.Ext(deckName)) + "-preview.png"
+	if err := fs.storage.Save(ctx, dto.FileTypeImages, name, bytes.NewReader(png)); err != nil {
+		return "", err
 	}
-	return uniqueName, nil
+	return name, nil
 }
 
-// Function to create directories if they don't exist
-func createDirIfNotExist(dir string) error {
-	if _, err := os.Stat(dir); os.IsNotExist(err) {
-		return os.MkdirAll(dir, 0755)
+// uploadDirs lists every directory uploads are written to.
+var uploadDirs = []string{dto.FileTypePDFs, dto.FileTypeImages}
+
+// CheckStorage verifies that every upload directory is writable by saving and
+// deleting a tiny probe file in each. It is cheap enough for readiness probes, and gives
+// up with ctx's error once ctx is done.
+func (fs *FileService) CheckStorage(ctx context.Context) error {
+	for _, dir := range uploadDirs {
+		name := ".healthcheck-" + uuid.NewString()
+
+		saveErr := fs.storage.Save(ctx, dir, name, strings.NewReader("ok"))
+		if saveErr != nil {
+			return fmt.Errorf("storage %s not writable: %w", dir, saveErr)
+		}
+		if err := fs.storage.Delete(dir, name); err != nil {
+			return fmt.Errorf("storage %s not writable: %w", dir, err)
+		}
 	}
 	return nil
 }
 
 // RetrieveFile retrieves a saved file based on its filename.
-// It determines the correct directory by inspecting the file extension.
+// It determines the correct directory by inspecting the file extension, and returns
+// ErrFileNotFound when there is no such file.
 func (fs *FileService) RetrieveFile(filename string) (io.ReadCloser, error) {
 	// Sanitize filename to prevent directory traversal attacks.
-	sanitized := filepath.Base(filename)
+	sanitized := utils.SanitizeFilename(filename)
+	if sanitized == "" {
+		return nil, fmt.Errorf("%w: invalid filename %q", ErrValidation, filename)
+	}
 	ext := filepath.Ext(sanitized)
 	destDir, err := getDestinationDir(ext)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: %v", ErrValidation, err)
 	}
 
-	filePath := filepath.Join(destDir, sanitized)
-	file, err := os.Open(filePath)
+	file, err := fs.storage.Open(destDir, sanitized)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, fmt.Errorf("file %q not found in directory %q", sanitized, destDir)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %q not found in directory %q", ErrFileNotFound, sanitized, destDir)
 		}
-		return nil, fmt.Errorf("error opening file %q: %w", filePath, err)
+		return nil, fmt.Errorf("error opening file %q: %w", filepath.Join(destDir, sanitized), err)
 	}
 
 	return file, nil
 }
 
+// DeleteFile removes a single stored file, found by extension like RetrieveFile. It
+// returns ErrFileNotFound when there is no such file.
+func (fs *FileService) DeleteFile(filename string) error {
+	// Sanitize filename to prevent directory traversal attacks.
+	sanitized := utils.SanitizeFilename(filename)
+	if sanitized == "" {
+		return fmt.Errorf("%w: invalid filename %q", ErrValidation, filename)
+	}
+	destDir, err := getDestinationDir(filepath.Ext(sanitized))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrValidation, err)
+	}
+
+	if err := fs.storage.Delete(destDir, sanitized); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("%w: %s", ErrFileNotFound, sanitized)
+		}
+		return fmt.Errorf("error deleting file %q: %w", filepath.Join(destDir, sanitized), err)
+	}
+
+	log.Printf("Deleted file %s", filepath.Join(destDir, sanitized))
+	return nil
+}
+
+// CDNURL returns the CDN location of a stored file when files of projects with the given
+// visibility are offloaded to the CDN. It reports false when the file should be served directly.
+func (fs *FileService) CDNURL(filename string, visibility dto.Visibility) (string, bool) {
+	if fs.cdnBaseURL == "" || !fs.cdnVisibilities[visibility] {
+		return "", false
+	}
+
+	sanitized := utils.SanitizeFilename(filename)
+	if sanitized == "" {
+		return "", false
+	}
+	dir, err := getDestinationDir(filepath.Ext(sanitized))
+	if err != nil {
+		return "", false
+	}
+
+	return fs.cdnBaseURL + "/" + dir + "/" + url.PathEscape(sanitized), true
+}
+
 // getDestinationDir returns the destination directory based on the file extension.
 func getDestinationDir(ext string) (string, error) {
 	ext = strings.ToLower(ext)
 	switch ext {
 	case ".pdf":
-		return "pdfs", nil
-	case ".jpg", ".jpeg", ".png", ".svg":
-		return "images", nil
+		return dto.FileTypePDFs, nil
+	case ".jpg", ".jpeg", ".png", ".svg", ".webp", ".gif":
+		return dto.FileTypeImages, nil
 	default:
 		return "", fmt.Errorf("unsupported file extension %q", ext)
 	}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/tarsuniversecentral/project-module/config"
+	"github.com/tarsuniversecentral/project-module/internal/dto"
+	"github.com/tarsuniversecentral/project-module/pkg/storage"
+)
+
+var (
+	testPDF = []byte("%PDF-1.4\n%test deck\n")
+	testPNG = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+)
+
+// newTestFileService returns a file service storing files under a temporary root,
+// along with that root.
+func newTestFileService(t *testing.T, cfg *config.Config) (*FileService, string) {
+	t.Helper()
+	root := t.TempDir()
+	cfg.UploadConcurrency = 2
+	return NewFileService(cfg, storage.NewLocalStorage(root)), root
+}
+
+// fileHeaders builds the multipart headers a request uploading files (name to content)
+// in field would carry.
+func fileHeaders(t *testing.T, field string, files map[string][]byte) []*multipart.FileHeader {
+	t.Helper()
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for name, content := range files {
+		part, err := w.CreateFormFile(field, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write(content)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	form, err := multipart.NewReader(&body, w.Boundary()).ReadForm(1 << 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { form.RemoveAll() })
+	return form.File[field]
+}
+
+// storedFiles lists the files in one storage directory under root.
+func storedFiles(t *testing.T, root, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(filepath.Join(root, dir))
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	return names
+}
+
+func TestProcessUploadsRemovesSavedPDFWhenAnotherFileFails(t *testing.T) {
+	fs, root := newTestFileService(t, &config.Config{})
+
+	pdfs := fileHeaders(t, "pdfs", map[string][]byte{"deck.pdf": testPDF})
+	images := fileHeaders(t, "images", map[string][]byte{"photo.png": []byte("not a png")})
+
+	if _, err := fs.ProcessUploads(context.Background(), pdfs, images); err == nil {
+		t.Fatal("ProcessUploads succeeded, want an error for the invalid image")
+	}
+	if left := storedFiles(t, root, dto.FileTypePDFs); len(left) > 0 {
+		t.Errorf("pdfs left behind after a failed upload: %v", left)
+	}
+}
+
+func TestFailedCreateRemovesSavedPDF(t *testing.T) {
+	fs, root := newTestFileService(t, &config.Config{})
+
+	pdfs := fileHeaders(t, "pdfs", map[string][]byte{"deck.pdf": testPDF})
+	saved, err := fs.ProcessUploads(context.Background(), pdfs, nil)
+	if err != nil {
+		t.Fatalf("ProcessUploads: %v", err)
+	}
+	if got := storedFiles(t, root, dto.FileTypePDFs); len(got) != 1 {
+		t.Fatalf("pdfs after upload = %v, want one file", got)
+	}
+
+	// What CreateProject's handler does when the project can't be stored.
+	if err := fs.DeleteSavedFiles(dto.ConstructFileResults(saved)); err != nil {
+		t.Fatalf("DeleteSavedFiles: %v", err)
+	}
+	if left := storedFiles(t, root, dto.FileTypePDFs); len(left) > 0 {
+		t.Errorf("pdfs left behind after a failed create: %v", left)
+	}
+}
+
+func TestFailedCreateRemovesPDFsImagesAndPreviews(t *testing.T) {
+	// A stand-in preview tool that writes a PNG header to {output}.
+	tool := filepath.Join(t.TempDir(), "preview.sh")
+	script := "#!/bin/sh\nprintf '\\211PNG\\r\\n\\032\\n' > \"$2\"\n"
+	if err := os.WriteFile(tool, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	fs, root := newTestFileService(t, &config.Config{
+		PDFPreviewCommand: "sh " + tool + " {input} {output}",
+		PDFPreviewTimeout: 10 * time.Second,
+	})
+
+	pdfs := fileHeaders(t, "pdfs", map[string][]byte{"deck.pdf": testPDF})
+	images := fileHeaders(t, "images", map[string][]byte{"photo.png": testPNG, "logo.png": testPNG})
+	saved, err := fs.ProcessUploads(context.Background(), pdfs, images)
+	if err != nil {
+		t.Fatalf("ProcessUploads: %v", err)
+	}
+	if len(saved.PDFPreviews) != 1 {
+		t.Fatalf("previews = %v, want one", saved.PDFPreviews)
+	}
+	if got := storedFiles(t, root, dto.FileTypeImages); len(got) != 3 {
+		t.Fatalf("images after upload = %v, want two images and a preview", got)
+	}
+
+	// CreateProjectTx rolls back whichever insert fails, including the image insert after
+	// the pitch decks are in; the handler then deletes everything ProcessUploads saved.
+	if err := fs.DeleteSavedFiles(dto.ConstructFileResults(saved)); err != nil {
+		t.Fatalf("DeleteSavedFiles: %v", err)
+	}
+	for _, dir := range []string{dto.FileTypePDFs, dto.FileTypeImages} {
+		if left := storedFiles(t, root, dir); len(left) > 0 {
+			t.Errorf("%s left behind after a failed create: %v", dir, left)
+		}
+	}
+}
+
+func TestCheckStorageHonorsContext(t *testing.T) {
+	fs, _ := newTestFileService(t, &config.Config{})
+
+	if err := fs.CheckStorage(context.Background()); err != nil {
+		t.Fatalf("CheckStorage: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := fs.CheckStorage(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("CheckStorage with a cancelled context = %v, want context.Canceled", err)
+	}
+}
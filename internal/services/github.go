@@ -0,0 +1,156 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tarsuniversecentral/project-module/internal/dto"
+)
+
+// maxImportedReadmeBytes caps how much of a README is copied into the description.
+const maxImportedReadmeBytes = 64 << 10
+
+var (
+	// ErrGithubRepoNotFound is returned when the repository doesn't exist or isn't visible to the configured token.
+	ErrGithubRepoNotFound = errors.New("github repository not found or private")
+	// ErrGithubUnavailable is returned when the GitHub API fails or returns an unexpected response.
+	ErrGithubUnavailable = errors.New("github api unavailable")
+)
+
+// GithubRateLimitError is returned when the GitHub API rate limit is exhausted.
+type GithubRateLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *GithubRateLimitError) Error() string {
+	return fmt.Sprintf("github api rate limit exceeded, retry after %s", e.RetryAfter.Round(time.Second))
+}
+
+// GithubImporter builds draft projects from public (or token-accessible) GitHub repositories.
+type GithubImporter struct {
+	client  *http.Client
+	apiBase string
+	token   string
+}
+
+func NewGithubImporter(apiBase, token string, timeout time.Duration) *GithubImporter {
+	return &GithubImporter{
+		client:  &http.Client{Timeout: timeout},
+		apiBase: strings.TrimRight(apiBase, "/"),
+		token:   token,
+	}
+}
+
+// ImportRepo fetches the repository metadata and README and returns an unsaved draft
+// project with the title, subtitle, description and github_link prefilled.
+func (g *GithubImporter) ImportRepo(repoURL string) (*dto.Project, error) {
+	owner, repo, err := parseGithubRepoURL(repoURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrValidation, err)
+	}
+
+	var meta struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		HTMLURL     string `json:"html_url"`
+	}
+	body, err := g.get(fmt.Sprintf("/repos/%s/%s", owner, repo), "application/vnd.github+json")
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(body, &meta); err != nil {
+		return nil, fmt.Errorf("%w: decoding repository metadata: %v", ErrGithubUnavailable, err)
+	}
+
+	project := &dto.Project{
+		Title:      meta.Name,
+		Subtitle:   meta.Description,
+		GithubLink: meta.HTMLURL,
+	}
+
+	// A repository without a README still yields a usable draft.
+	readme, err := g.get(fmt.Sprintf("/repos/%s/%s/readme", owner, repo), "application/vnd.github.raw")
+	switch {
+	case err == nil:
+		if len(readme) > maxImportedReadmeBytes {
+			readme = readme[:maxImportedReadmeBytes]
+		}
+		project.Description = strings.ToValidUTF8(string(readme), "")
+	case !errors.Is(err, ErrGithubRepoNotFound):
+		return nil, err
+	}
+
+	project.Normalize()
+	return project, nil
+}
+
+// get performs an authenticated GET against the GitHub API and maps error statuses.
+func (g *GithubImporter) get(path, accept string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, g.apiBase+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", accept)
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if g.token != "" {
+		req.Header.Set("Authorization", "Bearer "+g.token)
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrGithubUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return io.ReadAll(io.LimitReader(resp.Body, maxImportedReadmeBytes+1))
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, ErrGithubRepoNotFound
+	case resp.StatusCode == http.StatusTooManyRequests ||
+		(resp.StatusCode == http.StatusForbidden && resp.Header.Get("X-RateLimit-Remaining") == "0"):
+		return nil, &GithubRateLimitError{RetryAfter: githubRetryAfter(resp.Header)}
+	default:
+		return nil, fmt.Errorf("%w: unexpected status %d", ErrGithubUnavailable, resp.StatusCode)
+	}
+}
+
+// githubRetryAfter derives the wait from Retry-After or X-RateLimit-Reset, defaulting to a minute.
+func githubRetryAfter(h http.Header) time.Duration {
+	if secs, err := strconv.Atoi(h.Get("Retry-After")); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+	if reset, err := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		if wait := time.Until(time.Unix(reset, 0)); wait > 0 {
+			return wait
+		}
+	}
+	return time.Minute
+}
+
+// parseGithubRepoURL extracts the owner and repository name from a github.com URL.
+func parseGithubRepoURL(raw string) (string, string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return "", "", fmt.Errorf("invalid repository URL %q", raw)
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if host != "github.com" && host != "www.github.com" {
+		return "", "", fmt.Errorf("repository URL must be on github.com, got %q", host)
+	}
+
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("repository URL %q must include owner and repository", raw)
+	}
+
+	return parts[0], strings.TrimSuffix(parts[1], ".git"), nil
+}
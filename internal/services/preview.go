@@ -0,0 +1,104 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// maxPreviewBytes caps the size of a generated preview image.
+const maxPreviewBytes = 10 << 20
+
+// PreviewGenerator renders the first page of a PDF to a PNG by running an external tool.
+//
+// The command is split on whitespace. The placeholder {input} is replaced with the path of
+// the PDF, {output} with the path the PNG must be written to, and {output_base} with that
+// path minus its .png extension, for tools that append the extension themselves, e.g.
+//
+//	pdftoppm -png -singlefile -f 1 -l 1 -scale-to 800 {input} {output_base}
+type PreviewGenerator struct {
+	command []string
+	timeout time.Duration
+}
+
+// NewPreviewGenerator returns nil when command is empty, which disables previews.
+func NewPreviewGenerator(command string, timeout time.Duration) *PreviewGenerator {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return nil
+	}
+	return &PreviewGenerator{command: fields, timeout: timeout}
+}
+
+// Generate returns the PNG preview of the PDF read from pdf.
+func (g *PreviewGenerator) Generate(ctx context.Context, pdf io.Reader) ([]byte, error) {
+	dir, err := os.MkdirTemp("", "pdf-preview-*")
+	if err != nil {
+		return nil, fmt.Errorf("creating temp dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	input := filepath.Join(dir, "input.pdf")
+	outputBase := filepath.Join(dir, "preview")
+	output := outputBase + ".png"
+
+	if err := writeFile(input, pdf); err != nil {
+		return nil, err
+	}
+
+	replacer := strings.NewReplacer("{input}", input, "{output}", output, "{output_base}", outputBase)
+	args := make([]string, len(g.command))
+	for i, arg := range g.command {
+		args[i] = replacer.Replace(arg)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, g.timeout)
+	defer cancel()
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("running %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	f, err := os.Open(output)
+	if err != nil {
+		return nil, fmt.Errorf("reading preview: %w", err)
+	}
+	defer f.Close()
+
+	png, err := io.ReadAll(io.LimitReader(f, maxPreviewBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("reading preview: %w", err)
+	}
+	if len(png) > maxPreviewBytes {
+		return nil, fmt.Errorf("preview is larger than %d bytes", maxPreviewBytes)
+	}
+	if http.DetectContentType(png) != "image/png" {
+		return nil, fmt.Errorf("preview tool did not produce a PNG")
+	}
+	return png, nil
+}
+
+func writeFile(path string, r io.Reader) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("creating %s: %w", path, err)
+	}
+	_, err = io.Copy(f, r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return nil
+}
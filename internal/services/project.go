@@ -1,26 +1,97 @@
 package services
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"log"
+	"net/url"
 	"strings"
+	"time"
 
+	"github.com/tarsuniversecentral/project-module/config"
 	"github.com/tarsuniversecentral/project-module/internal/dto"
 	"github.com/tarsuniversecentral/project-module/internal/models"
+	"github.com/tarsuniversecentral/project-module/pkg/tracing"
+	"github.com/tarsuniversecentral/project-module/pkg/utils"
 )
 
 type ProjectService struct {
-	model *models.ProjectModel
+	model  *models.ProjectModel
+	cfg    *config.Config
+	github *GithubImporter
+	events *EventBroker
 }
 
-func NewProjectService(model *models.ProjectModel) *ProjectService {
-	return &ProjectService{model: model}
+func NewProjectService(model *models.ProjectModel, cfg *config.Config) *ProjectService {
+	return &ProjectService{
+		model:  model,
+		cfg:    cfg,
+		github: NewGithubImporter(cfg.GithubAPIURL, cfg.GithubToken, cfg.GithubAPITimeout),
+		events: NewEventBroker(cfg.EventMaxSubscribers, cfg.RetryAfter.Overloaded),
+	}
 }
 
-func (s *ProjectService) CreateProject(project dto.Project) (*dto.Project, error) {
+// ValidateProject normalizes the project's fields and checks them, returning an
+// error wrapping ErrValidation when the input is unacceptable.
+func (s *ProjectService) ValidateProject(project *dto.Project) error {
+	project.Normalize()
+
+	if err := project.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrValidation, err)
+	}
+
+	// The single industry is the primary one; older clients send only that.
+	project.Industries = dto.NormalizeIndustries(append([]string{project.Industry}, project.Industries...))
+	if err := dto.ValidateIndustries(project.Industries); err != nil {
+		return fmt.Errorf("%w: %v", ErrValidation, err)
+	}
+	project.Industry = ""
+	if len(project.Industries) > 0 {
+		project.Industry = project.Industries[0]
+	}
+
+	project.LookingFor = dto.NormalizeLookingFor(project.LookingFor)
+	if err := dto.ValidateLookingFor(project.LookingFor); err != nil {
+		return fmt.Errorf("%w: %v", ErrValidation, err)
+	}
+
+	if project.GithubLink != "" {
+		if err := dto.ValidateGithubLink(project.GithubLink, s.cfg.GithubLinkAllowedHosts); err != nil {
+			return fmt.Errorf("%w: %v", ErrValidation, err)
+		}
+	}
+
+	if project.Visibility == "" {
+		project.Visibility = dto.VisibilityPublic
+	}
+	if err := dto.ValidateVisibility(project.Visibility); err != nil {
+		return fmt.Errorf("%w: %v", ErrValidation, err)
+	}
+
+	if project.Currency == "" {
+		project.Currency = dto.DefaultCurrency
+	}
+	if err := dto.ValidateCurrency(project.Currency); err != nil {
+		return fmt.Errorf("%w: %v", ErrValidation, err)
+	}
+
+	return nil
+}
+
+func (s *ProjectService) CreateProject(ctx context.Context, project dto.Project) (*dto.Project, error) {
+
+	ctx, span := tracing.Start(ctx, "ProjectService.CreateProject")
+	defer span.End()
+
+	if err := s.ValidateProject(&project); err != nil {
+		return nil, err
+	}
 
 	lookingForStr := strings.Join(project.LookingFor, ",")
 
-	err := s.model.CreateProjectTx(&project, lookingForStr)
+	err := s.model.CreateProjectTxContext(ctx, &project, lookingForStr)
 	if err != nil {
 		return nil, err
 	}
@@ -28,65 +99,900 @@ func (s *ProjectService) CreateProject(project dto.Project) (*dto.Project, error
 	return &project, nil
 }
 
-func (s *ProjectService) GetProject(id int) (*dto.Project, error) {
+// UpdateProject validates and applies a partial update of the user's project, returning the
+// updated project.
+func (s *ProjectService) UpdateProject(ctx context.Context, id, userID int, patch dto.ProjectPatch) (*dto.Project, error) {
+
+	ctx, span := tracing.Start(ctx, "ProjectService.UpdateProject")
+	defer span.End()
+
+	if err := s.validatePatch(&patch); err != nil {
+		return nil, err
+	}
+
+	if err := s.authorizeOwner(ctx, id, userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.model.UpdateProjectContext(ctx, id, patch); err != nil {
+		return nil, err
+	}
+
+	project, err := s.model.GetProjectFullDetailsContext(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if canView(project.Visibility) {
+		s.events.Publish(dto.NewProjectStatusEvent(project))
+	} else {
+		s.events.Publish(dto.ProjectEvent{Type: dto.ProjectEventRemoved, ProjectID: id})
+	}
+	return project, nil
+}
+
+// validatePatch normalizes and checks the fields present in the patch, applying the
+// same rules as project creation.
+func (s *ProjectService) validatePatch(patch *dto.ProjectPatch) error {
+	if patch.Title.Set {
+		patch.Title.Value = dto.NormalizeWhitespace(patch.Title.Value)
+		if patch.Title.Null || patch.Title.Value == "" {
+			return fmt.Errorf("%w: title cannot be cleared", ErrValidation)
+		}
+		if err := dto.ValidateTextLength("title", patch.Title.Value, dto.MaxTitleLength); err != nil {
+			return fmt.Errorf("%w: %v", ErrValidation, err)
+		}
+	}
+
+	if patch.Subtitle.Set {
+		patch.Subtitle.Value = dto.NormalizeWhitespace(patch.Subtitle.Value)
+		if err := dto.ValidateTextLength("subtitle", patch.Subtitle.Value, dto.MaxSubtitleLength); err != nil {
+			return fmt.Errorf("%w: %v", ErrValidation, err)
+		}
+	}
+
+	if patch.Description.Set {
+		if err := dto.ValidateTextLength("description", patch.Description.Value, dto.MaxDescriptionLength); err != nil {
+			return fmt.Errorf("%w: %v", ErrValidation, err)
+		}
+	}
+
+	if patch.ProjectValue.Set && !patch.ProjectValue.Null {
+		if err := dto.ValidateProjectValue(patch.ProjectValue.Value); err != nil {
+			return fmt.Errorf("%w: %v", ErrValidation, err)
+		}
+	}
+
+	if err := syncIndustryPatch(patch); err != nil {
+		return err
+	}
+
+	if patch.LookingFor.Set {
+		patch.LookingFor.Value = dto.NormalizeLookingFor(patch.LookingFor.Value)
+		if err := dto.ValidateLookingFor(patch.LookingFor.Value); err != nil {
+			return fmt.Errorf("%w: %v", ErrValidation, err)
+		}
+	}
+
+	if patch.GithubLink.Set {
+		patch.GithubLink.Value = dto.NormalizeGithubLink(patch.GithubLink.Value)
+	}
+	if patch.GithubLink.Set && patch.GithubLink.Value != "" {
+		if err := dto.ValidateTextLength("github_link", patch.GithubLink.Value, dto.MaxGithubLinkLength); err != nil {
+			return fmt.Errorf("%w: %v", ErrValidation, err)
+		}
+		if err := dto.ValidateGithubLink(patch.GithubLink.Value, s.cfg.GithubLinkAllowedHosts); err != nil {
+			return fmt.Errorf("%w: %v", ErrValidation, err)
+		}
+	}
+
+	if patch.Currency.Set {
+		patch.Currency.Value = dto.NormalizeCurrency(patch.Currency.Value)
+		if patch.Currency.Null || patch.Currency.Value == "" {
+			return fmt.Errorf("%w: currency cannot be cleared", ErrValidation)
+		}
+		if err := dto.ValidateCurrency(patch.Currency.Value); err != nil {
+			return fmt.Errorf("%w: %v", ErrValidation, err)
+		}
+	}
+
+	if patch.Visibility.Set {
+		if patch.Visibility.Null {
+			return fmt.Errorf("%w: visibility cannot be cleared", ErrValidation)
+		}
+		if err := dto.ValidateVisibility(patch.Visibility.Value); err != nil {
+			return fmt.Errorf("%w: %v", ErrValidation, err)
+		}
+	}
+
+	return nil
+}
+
+// syncIndustryPatch keeps industry and industries consistent: industry is always the first
+// entry of industries, whichever of the two the client sent.
+func syncIndustryPatch(patch *dto.ProjectPatch) error {
+	switch {
+	case patch.Industries.Set:
+		values := patch.Industries.Value
+		if patch.Industry.Set && !patch.Industry.Null {
+			values = append([]string{patch.Industry.Value}, values...)
+		}
+		industries := dto.NormalizeIndustries(values)
+		if err := dto.ValidateIndustries(industries); err != nil {
+			return fmt.Errorf("%w: %v", ErrValidation, err)
+		}
+		patch.Industries = dto.PatchField[[]string]{Set: true, Value: industries}
+	case patch.Industry.Set:
+		industries := []string{}
+		if !patch.Industry.Null {
+			industries = dto.NormalizeIndustries([]string{patch.Industry.Value})
+		}
+		if err := dto.ValidateIndustries(industries); err != nil {
+			return fmt.Errorf("%w: %v", ErrValidation, err)
+		}
+		patch.Industries = dto.PatchField[[]string]{Set: true, Value: industries}
+	default:
+		return nil
+	}
+
+	if len(patch.Industries.Value) == 0 {
+		patch.Industry = dto.PatchField[string]{Set: true, Null: true}
+	} else {
+		patch.Industry = dto.PatchField[string]{Set: true, Value: patch.Industries.Value[0]}
+	}
+	return nil
+}
+
+// ImportFromGithub returns a draft project prefilled from a GitHub repository. It is not saved.
+func (s *ProjectService) ImportFromGithub(repoURL string) (*dto.Project, error) {
+	return s.github.ImportRepo(repoURL)
+}
+
+// ListProjects returns one page of projects matching the filter in the requested order.
+func (s *ProjectService) ListProjects(ctx context.Context, filter dto.ProjectFilter, page, perPage int, sort string) (*dto.PaginatedResponse[dto.Project], error) {
+
+	ctx, span := tracing.Start(ctx, "ProjectService.ListProjects")
+	defer span.End()
+
+	if sort == "" {
+		sort = models.DefaultProjectSort
+	}
+	if !models.IsValidProjectSort(sort) {
+		return nil, fmt.Errorf("%w: unknown sort %q", ErrValidation, sort)
+	}
+
+	if err := dto.ValidateLookingFor(filter.LookingFor); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrValidation, err)
+	}
+
+	total, lastModified, err := s.model.ProjectListStatsContext(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	projects, err := s.model.FilterProjectsContext(ctx, filter, dto.Offset(page, perPage), perPage, sort)
+	if err != nil {
+		return nil, err
+	}
+
+	response := dto.NewPaginatedResponse(projects, page, perPage, total)
+	response.LastModified = lastModified
+	return &response, nil
+}
+
+// ListProjectsByOwner returns one page of the user's own projects, including unlisted and
+// private ones, in the requested order.
+func (s *ProjectService) ListProjectsByOwner(ctx context.Context, ownerID, page, perPage int, sort string) (*dto.PaginatedResponse[dto.Project], error) {
 
-	if err := s.validateProjectExists(id); err != nil {
+	if sort == "" {
+		sort = models.DefaultProjectSort
+	}
+	if !models.IsValidProjectSort(sort) {
+		return nil, fmt.Errorf("%w: unknown sort %q", ErrValidation, sort)
+	}
+
+	total, lastModified, err := s.model.ProjectListStatsContext(ctx, dto.ProjectFilter{OwnerID: ownerID})
+	if err != nil {
 		return nil, err
 	}
 
-	project, err := s.model.GetProjectFullDetails(id)
+	projects, err := s.model.GetProjectsByOwnerContext(ctx, ownerID, dto.Offset(page, perPage), perPage, sort)
 	if err != nil {
 		return nil, err
 	}
 
+	response := dto.NewPaginatedResponse(projects, page, perPage, total)
+	response.LastModified = lastModified
+	return &response, nil
+}
+
+// CountProjects returns how many projects match the filter; it is the total that
+// ListProjects reports for the same filter.
+func (s *ProjectService) CountProjects(ctx context.Context, filter dto.ProjectFilter) (int, error) {
+
+	if err := dto.ValidateLookingFor(filter.LookingFor); err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrValidation, err)
+	}
+
+	return s.model.CountProjectsContext(ctx, filter)
+}
+
+// Industries returns the industries in use by public projects, for filter choices.
+func (s *ProjectService) Industries(ctx context.Context) ([]string, error) {
+	return s.model.GetDistinctIndustriesContext(ctx)
+}
+
+// ValueByIndustry returns project_value totals and averages per industry.
+func (s *ProjectService) ValueByIndustry(ctx context.Context) ([]dto.IndustryValueStat, error) {
+	return s.model.SumValueByIndustryContext(ctx)
+}
+
+// GetProject returns a public or unlisted project by ID. Private projects are reported
+// as not found unless userID is their owner or previewToken is a valid preview token for
+// the project. userID is 0 for anonymous callers.
+func (s *ProjectService) GetProject(ctx context.Context, id, userID int, previewToken string) (*dto.Project, error) {
+
+	ctx, span := tracing.Start(ctx, "ProjectService.GetProject")
+	defer span.End()
+
+	project, err := s.model.GetProjectFullDetailsContext(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, projectNotFound(id)
+		}
+		return nil, err
+	}
+
+	if !canView(project.Visibility) {
+		// Owners looking at their own private project aren't counted as views either.
+		if isOwner(project.OwnerID, userID) {
+			return project, nil
+		}
+		ok, err := s.checkPreviewToken(ctx, id, previewToken)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, projectNotFound(id)
+		}
+		// Draft previews aren't counted as views.
+		return project, nil
+	}
+
+	// Count this fetch as a view and include it in the response.
+	if err := s.model.IncrementViewCountContext(ctx, id); err != nil {
+		return nil, err
+	}
+	project.ViewCount++
+	s.events.Publish(dto.NewProjectStatusEvent(project))
+
 	return project, nil
 }
 
-func (s *ProjectService) AddTeamMember(teamMember *dto.TeamMember) error {
+// SetLike records (liked) or removes the user's like of a project they can view and
+// returns the project's like count afterwards. Liking or unliking twice is a no-op.
+func (s *ProjectService) SetLike(ctx context.Context, projectID, userID int, liked bool) (int, error) {
+
+	ctx, span := tracing.Start(ctx, "ProjectService.SetLike")
+	defer span.End()
+
+	visibility, err := s.model.GetProjectVisibilityContext(ctx, projectID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, projectNotFound(projectID)
+		}
+		return 0, err
+	}
+	if !canView(visibility) {
+		return 0, projectNotFound(projectID)
+	}
+
+	count, err := s.model.SetProjectLikeTxContext(ctx, projectID, userID, liked)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, projectNotFound(projectID)
+		}
+		return 0, err
+	}
+
+	s.publishStatus(ctx, projectID, func(p *dto.Project) { p.LikeCount = count })
+	return count, nil
+}
+
+// maxLikeBatchItems caps how many items one like batch can hold.
+const maxLikeBatchItems = 100
+
+// SetLikes applies a batch of likes and unlikes for the user in one transaction and returns
+// a result for each item, in order. When several items name the same project only the last
+// is applied; the earlier ones are reported as superseded. Items that repeat the current
+// state are reported as unchanged, and projects the user can't view as not found.
+func (s *ProjectService) SetLikes(ctx context.Context, userID int, items []dto.LikeBatchItem) ([]dto.LikeBatchResult, error) {
+
+	ctx, span := tracing.Start(ctx, "ProjectService.SetLikes")
+	defer span.End()
+
+	if len(items) == 0 {
+		return nil, fmt.Errorf("%w: at least one item is required", ErrValidation)
+	}
+	if len(items) > maxLikeBatchItems {
+		return nil, fmt.Errorf("%w: at most %d items can be sent at once", ErrValidation, maxLikeBatchItems)
+	}
+
+	last := make(map[int]int, len(items))
+	for i, item := range items {
+		if item.ProjectID <= 0 {
+			return nil, fmt.Errorf("%w: invalid project id %d", ErrValidation, item.ProjectID)
+		}
+		if item.Action != dto.LikeActionLike && item.Action != dto.LikeActionUnlike {
+			return nil, fmt.Errorf("%w: action must be %q or %q", ErrValidation, dto.LikeActionLike, dto.LikeActionUnlike)
+		}
+		last[item.ProjectID] = i
+	}
+
+	likes := make(map[int]bool, len(last))
+	for id, i := range last {
+		visibility, err := s.model.GetProjectVisibilityContext(ctx, id)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			return nil, err
+		}
+		if canView(visibility) {
+			likes[id] = items[i].Action == dto.LikeActionLike
+		}
+	}
+
+	outcomes, err := s.model.SetProjectLikesTxContext(ctx, userID, likes)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]dto.LikeBatchResult, len(items))
+	for i, item := range items {
+		result := dto.LikeBatchResult{ProjectID: item.ProjectID, Action: item.Action}
+		outcome, found := outcomes[item.ProjectID]
+		switch {
+		case last[item.ProjectID] != i:
+			result.Status = dto.LikeStatusSuperseded
+		case !found:
+			result.Status = dto.LikeStatusNotFound
+		default:
+			result.Status = dto.LikeStatusUnchanged
+			if outcome.Changed {
+				result.Status = dto.LikeStatusApplied
+			}
+			result.Liked = item.Action == dto.LikeActionLike
+			result.LikeCount = outcome.LikeCount
+		}
+		results[i] = result
+	}
+
+	for id, outcome := range outcomes {
+		if outcome.Changed {
+			count := outcome.LikeCount
+			s.publishStatus(ctx, id, func(p *dto.Project) { p.LikeCount = count })
+		}
+	}
+
+	return results, nil
+}
+
+// publishStatus sends a status event with the project's current state to its subscribers.
+// adjust overrides values the caller already knows, which a lagging replica may not show yet.
+// Failures are logged; events are best-effort.
+func (s *ProjectService) publishStatus(ctx context.Context, id int, adjust func(p *dto.Project)) {
+	project, err := s.model.GetProjectFullDetailsContext(ctx, id)
+	if err != nil {
+		log.Printf("Error loading project %d for its status event: %v", id, err)
+		return
+	}
+	adjust(project)
+	s.events.Publish(dto.NewProjectStatusEvent(project))
+}
+
+// SubscribeEvents subscribes to the events of a project the user can view, which includes
+// their own private projects. userID is 0 for anonymous callers. The caller must close the
+// subscription when done.
+func (s *ProjectService) SubscribeEvents(ctx context.Context, id, userID int) (*EventSubscription, error) {
+
+	visibility, err := s.model.GetProjectVisibilityContext(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, projectNotFound(id)
+		}
+		return nil, err
+	}
+	ok, err := s.canViewAs(ctx, id, visibility, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, projectNotFound(id)
+	}
+
+	return s.events.Subscribe(id)
+}
+
+// EventHeartbeatInterval returns how often idle event streams send a keep-alive.
+func (s *ProjectService) EventHeartbeatInterval() time.Duration {
+	return s.cfg.EventHeartbeatInterval
+}
+
+// CheckFileAccess reports whether the stored file may be served and returns the project
+// it belongs to and its original name. Files are only served when they belong to a project
+// the caller can view; anything else is ErrFileNotFound. userID is 0 for anonymous callers.
+func (s *ProjectService) CheckFileAccess(ctx context.Context, filename string, userID int, previewToken string) (dto.FileReference, error) {
+
+	ref, err := s.model.GetFileReferenceContext(ctx, filename)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return dto.FileReference{}, fmt.Errorf("%w: %s", ErrFileNotFound, filename)
+		}
+		return dto.FileReference{}, fmt.Errorf("failed to check file access: %w", err)
+	}
+
+	if !canView(ref.Visibility) {
+		ok, err := s.isProjectOwner(ctx, ref.ProjectID, userID)
+		if err == nil && !ok {
+			ok, err = s.checkPreviewToken(ctx, ref.ProjectID, previewToken)
+		}
+		if err != nil {
+			return dto.FileReference{}, err
+		}
+		if !ok {
+			return dto.FileReference{}, fmt.Errorf("%w: %s", ErrFileNotFound, filename)
+		}
+	}
+	return ref, nil
+}
+
+// CheckFileUnreferenced returns ErrFileInUse when a project references the stored file,
+// so it can't be deleted without breaking that project.
+func (s *ProjectService) CheckFileUnreferenced(ctx context.Context, filename string) error {
+
+	_, err := s.model.GetFileReferenceContext(ctx, filename)
+	if err == nil {
+		return fmt.Errorf("%w: %s is attached to a project; remove it from the project instead", ErrFileInUse, filename)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	return fmt.Errorf("failed to check file references: %w", err)
+}
+
+// CreatePreviewToken issues a token that lets anyone holding it read the user's project,
+// even while it is private, until the token expires or is revoked.
+func (s *ProjectService) CreatePreviewToken(ctx context.Context, id, userID int) (*dto.PreviewTokenResponse, error) {
+
+	if err := s.authorizeOwner(ctx, id, userID); err != nil {
+		return nil, err
+	}
+
+	version, err := s.model.GetPreviewTokenVersionContext(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, projectNotFound(id)
+		}
+		return nil, err
+	}
+
+	// Tokens carry whole seconds.
+	expiresAt := time.Now().Add(s.cfg.PreviewTokenTTL).Truncate(time.Second)
+	token := utils.EncodePreviewToken(utils.PreviewToken{ProjectID: id, Version: version, ExpiresAt: expiresAt})
+
+	return &dto.PreviewTokenResponse{
+		Token:     token,
+		ExpiresAt: dto.NewTime(expiresAt),
+		URL:       fmt.Sprintf("%s/projects/%d?preview_token=%s", dto.APIBasePath, id, url.QueryEscape(token)),
+	}, nil
+}
+
+// RevokePreviewTokens invalidates every preview token issued for the user's project so far.
+func (s *ProjectService) RevokePreviewTokens(ctx context.Context, id, userID int) error {
+
+	if err := s.authorizeOwner(ctx, id, userID); err != nil {
+		return err
+	}
+
+	if err := s.model.BumpPreviewTokenVersionContext(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return projectNotFound(id)
+		}
+		return err
+	}
+	return nil
+}
+
+// checkPreviewToken reports whether token is an unexpired, unrevoked preview token for
+// the project. An empty or malformed token is simply not valid.
+func (s *ProjectService) checkPreviewToken(ctx context.Context, projectID int, token string) (bool, error) {
+	if token == "" {
+		return false, nil
+	}
+
+	t, err := utils.DecodePreviewToken(token)
+	if err != nil || t.ProjectID != projectID || !time.Now().Before(t.ExpiresAt) {
+		return false, nil
+	}
+
+	version, err := s.model.GetPreviewTokenVersionContext(ctx, projectID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to check preview token: %w", err)
+	}
+	return t.Version == version, nil
+}
+
+// canView reports whether a project with the given visibility can be read directly.
+// Unlisted projects are readable by anyone who has the link.
+func canView(visibility dto.Visibility) bool {
+	return visibility == dto.VisibilityPublic || visibility == dto.VisibilityUnlisted
+}
+
+// isOwner reports whether userID is the project's recorded owner. Projects from before
+// owners were recorded have none, and anonymous callers are user 0.
+func isOwner(ownerID, userID int) bool {
+	return ownerID != 0 && ownerID == userID
+}
+
+// canViewAs reports whether the user can read a project with the given visibility: any
+// project canView allows, and private projects they own.
+func (s *ProjectService) canViewAs(ctx context.Context, projectID int, visibility dto.Visibility, userID int) (bool, error) {
+	if canView(visibility) {
+		return true, nil
+	}
+	return s.isProjectOwner(ctx, projectID, userID)
+}
+
+// isProjectOwner reports whether userID owns the project.
+func (s *ProjectService) isProjectOwner(ctx context.Context, projectID, userID int) (bool, error) {
+	if userID == 0 {
+		return false, nil
+	}
+	ownerID, _, err := s.model.GetProjectOwnerContext(ctx, projectID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to check project owner: %w", err)
+	}
+	return isOwner(ownerID, userID), nil
+}
+
+// AddTeamMember adds the member to a project the user owns.
+func (s *ProjectService) AddTeamMember(ctx context.Context, teamMember *dto.TeamMember, userID int) error {
+
+	if err := s.authorizeOwner(ctx, teamMember.ProjectID, userID); err != nil {
+		return err
+	}
+
+	// A new lead replaces the current one as part of the insert.
+	return s.model.InsertTeamMemberContext(ctx, teamMember)
+}
+
+// GetTeamMembers returns one page of the members of a project the user can view. userID
+// is 0 for anonymous callers.
+func (s *ProjectService) GetTeamMembers(ctx context.Context, id, userID, page, perPage int) (*dto.PaginatedResponse[*dto.TeamMember], error) {
+
+	visibility, err := s.model.GetProjectVisibilityContext(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, projectNotFound(id)
+		}
+		return nil, fmt.Errorf("failed to validate project: %w", err)
+	}
+	ok, err := s.canViewAs(ctx, id, visibility, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, projectNotFound(id)
+	}
+
+	total, lastModified, err := s.model.TeamMemberListStatsContext(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	teamMembers, err := s.model.GetTeamMembersContext(ctx, id, dto.Offset(page, perPage), perPage)
+	if err != nil {
+		return nil, err
+	}
+
+	response := dto.NewPaginatedResponse(teamMembers, page, perPage, total)
+	response.LastModified = lastModified
+	return &response, nil
+}
+
+// GetTeamMember returns a team member by ID. Members of projects the caller can't view are
+// reported as not found, like the project itself. userID is 0 for anonymous callers.
+func (s *ProjectService) GetTeamMember(ctx context.Context, id, userID int) (*dto.TeamMember, error) {
+
+	member, err := s.model.GetTeamMemberByIDContext(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, teamMemberNotFound(id)
+		}
+		return nil, fmt.Errorf("failed to fetch team member: %w", err)
+	}
+
+	visibility, err := s.model.GetProjectVisibilityContext(ctx, member.ProjectID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, teamMemberNotFound(id)
+		}
+		return nil, fmt.Errorf("failed to validate project: %w", err)
+	}
+	ok, err := s.canViewAs(ctx, member.ProjectID, visibility, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, teamMemberNotFound(id)
+	}
+
+	return member, nil
+}
+
+// UpdateTeamMemberRole sets the role of a member of the user's project. version must match
+// the member's current version, or ErrVersionConflict is returned.
+func (s *ProjectService) UpdateTeamMemberRole(ctx context.Context, id, userID int, role string, version int) error {
+	if version < 1 {
+		return fmt.Errorf("%w: version must be positive", ErrValidation)
+	}
 
-	if err := s.validateProjectExists(teamMember.ProjectID); err != nil {
+	if err := s.authorizeMemberOwner(ctx, id, userID); err != nil {
 		return err
 	}
 
-	err := s.model.InsertTeamMember(teamMember)
+	err := s.model.UpdateTeamMemberRoleContext(ctx, id, role, version)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return teamMemberNotFound(id)
+	case errors.Is(err, models.ErrVersionMismatch):
+		return fmt.Errorf("%w: team member %d has changed since version %d", ErrVersionConflict, id, version)
+	case err != nil:
+		return err
+	}
+
+	return nil
+}
+
+// DeleteTeamMember removes a member of the user's project.
+func (s *ProjectService) DeleteTeamMember(ctx context.Context, id, userID int) error {
+
+	if err := s.authorizeMemberOwner(ctx, id, userID); err != nil {
+		return err
+	}
+
+	if err := s.model.DeleteTeamMemberContext(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return teamMemberNotFound(id)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// maxReassignMembers caps how many members one reassignment can move.
+const maxReassignMembers = 500
+
+// ReassignTeamMembers moves the members to the target project on behalf of the user and
+// returns how many moved. Either all members move or none do; each move is recorded
+// against the user.
+func (s *ProjectService) ReassignTeamMembers(ctx context.Context, req dto.ReassignTeamMembersRequest, userID int) (int, error) {
+
+	if req.TargetProjectID <= 0 {
+		return 0, fmt.Errorf("%w: target_project_id is required", ErrValidation)
+	}
+	if len(req.MemberIDs) == 0 {
+		return 0, fmt.Errorf("%w: member_ids cannot be empty", ErrValidation)
+	}
+
+	seen := make(map[int]struct{}, len(req.MemberIDs))
+	memberIDs := make([]int, 0, len(req.MemberIDs))
+	for _, id := range req.MemberIDs {
+		if id <= 0 {
+			return 0, fmt.Errorf("%w: invalid member id %d", ErrValidation, id)
+		}
+		if _, dup := seen[id]; dup {
+			continue
+		}
+		seen[id] = struct{}{}
+		memberIDs = append(memberIDs, id)
+	}
+	if len(memberIDs) > maxReassignMembers {
+		return 0, fmt.Errorf("%w: at most %d members can be reassigned at once", ErrValidation, maxReassignMembers)
+	}
+
+	if err := s.validateProjectExists(ctx, req.TargetProjectID); err != nil {
+		return 0, err
+	}
+
+	moved, err := s.model.ReassignTeamMembersTxContext(ctx, memberIDs, req.TargetProjectID, userID)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("%w: one or more of the team members do not exist", ErrTeamMemberNotFound)
+		}
+		return 0, err
+	}
+
+	log.Printf("User %d reassigned %d team member(s) to project %d: %v", userID, moved, req.TargetProjectID, memberIDs)
+	return moved, nil
+}
+
+// ReconcileCounts corrects the projects' denormalized counts on behalf of the user, as the
+// CountReconciler does on its schedule, and returns how many projects it changed.
+func (s *ProjectService) ReconcileCounts(ctx context.Context, userID int) (int64, error) {
+	corrected, err := s.model.ReconcileLikeCountsContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	log.Printf("User %d reconciled counts, correcting %d projects", userID, corrected)
+	return corrected, nil
+}
+
+// DeleteProject removes the user's project and all of its rows, returning the files it
+// referenced so the caller can delete them from storage.
+func (s *ProjectService) DeleteProject(ctx context.Context, id, userID int) (dto.SavedFiles, error) {
+
+	ctx, span := tracing.Start(ctx, "ProjectService.DeleteProject")
+	defer span.End()
+
+	if err := s.authorizeOwner(ctx, id, userID); err != nil {
+		return dto.SavedFiles{}, err
+	}
+
+	files, err := s.model.DeleteProjectTxContext(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return dto.SavedFiles{}, projectNotFound(id)
+		}
+		return dto.SavedFiles{}, err
+	}
+
+	s.events.Publish(dto.ProjectEvent{Type: dto.ProjectEventRemoved, ProjectID: id})
+	return files, nil
+}
+
+// SetProjectLead makes the member the lead of the user's project, clearing the previous
+// lead. It returns ErrTeamMemberNotFound when the member isn't on the project.
+func (s *ProjectService) SetProjectLead(ctx context.Context, projectID, memberID, userID int) error {
+
+	if err := s.authorizeOwner(ctx, projectID, userID); err != nil {
 		return err
 	}
 
+	if err := s.model.SetTeamMemberLeadTxContext(ctx, projectID, memberID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: team member with ID %d is not on project %d", ErrTeamMemberNotFound, memberID, projectID)
+		}
+		return err
+	}
 	return nil
 }
 
-func (s *ProjectService) GetTeamMembers(id int) ([]*dto.TeamMember, error) {
+// DeleteProjectFiles removes all files of the given type ("images" or "pdfs") from the user's
+// project and returns them so the caller can delete the stored files once the rows are gone.
+func (s *ProjectService) DeleteProjectFiles(ctx context.Context, projectID, userID int, fileType string) ([]dto.FileResult, error) {
 
-	if err := s.validateProjectExists(id); err != nil {
+	if err := s.authorizeOwner(ctx, projectID, userID); err != nil {
 		return nil, err
 	}
 
-	teamMembers, err := s.model.GetTeamMembers(id)
+	var (
+		paths    []string
+		previews []string
+		err      error
+	)
+	switch fileType {
+	case dto.FileTypeImages:
+		paths, err = s.model.DeleteProjectImagesTxContext(ctx, projectID)
+	case dto.FileTypePDFs:
+		paths, previews, err = s.model.DeleteProjectPitchDecksTxContext(ctx, projectID)
+	default:
+		return nil, fmt.Errorf("unsupported file type %q", fileType)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	return teamMembers, nil
+	results := make([]dto.FileResult, 0, len(paths)+len(previews))
+	for _, path := range paths {
+		results = append(results, dto.FileResult{FileType: fileType, Filename: path})
+	}
+	// Pitch deck previews are stored with the images.
+	for _, path := range previews {
+		results = append(results, dto.FileResult{FileType: dto.FileTypeImages, Filename: path})
+	}
+	return results, nil
 }
 
-func (s *ProjectService) UpdateTeamMemberRole(id int, role string) error {
+// DeleteProjectFile removes one stored file from the user's project and returns the files to
+// delete from storage. It returns ErrFileNotFound when the file doesn't belong to the project.
+func (s *ProjectService) DeleteProjectFile(ctx context.Context, projectID, userID int, filename string) ([]dto.FileResult, error) {
 
-	err := s.model.UpdateTeamMemberRole(id, role)
+	if err := s.authorizeOwner(ctx, projectID, userID); err != nil {
+		return nil, err
+	}
+
+	files, err := s.model.DeleteProjectFileTxContext(ctx, projectID, filename)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %s does not belong to project %d", ErrFileNotFound, filename, projectID)
+		}
+		return nil, fmt.Errorf("failed to delete project file: %w", err)
+	}
+	return files, nil
+}
+
+// SetPrimaryImage makes the user's project's image with the given stored filename its cover.
+// It returns ErrFileNotFound when the image doesn't belong to the project.
+func (s *ProjectService) SetPrimaryImage(ctx context.Context, projectID, userID int, filename string) error {
+
+	if err := s.authorizeOwner(ctx, projectID, userID); err != nil {
 		return err
 	}
 
+	if err := s.model.SetPrimaryImageTxContext(ctx, projectID, filename); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: %s is not an image of project %d", ErrFileNotFound, filename, projectID)
+		}
+		return fmt.Errorf("failed to set primary image: %w", err)
+	}
 	return nil
 }
 
-func (s *ProjectService) validateProjectExists(id int) error {
-	exists, err := s.model.ProjectExists(id)
+func (s *ProjectService) validateProjectExists(ctx context.Context, id int) error {
+	exists, err := s.model.ProjectExistsContext(ctx, id)
 	if err != nil {
 		return fmt.Errorf("failed to validate project: %w", err)
 	}
 	if !exists {
-		return fmt.Errorf("project with ID %d does not exist", id)
+		return projectNotFound(id)
+	}
+	return nil
+}
+
+// authorizeOwner returns nil when the user owns the project. Otherwise it returns
+// ErrForbidden, or ErrProjectNotFound when the user can't see the project either, so
+// private projects don't reveal that they exist. Projects from before owners were
+// recorded have no owner and can't be changed through the API.
+func (s *ProjectService) authorizeOwner(ctx context.Context, projectID, userID int) error {
+	ownerID, visibility, err := s.model.GetProjectOwnerContext(ctx, projectID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return projectNotFound(projectID)
+		}
+		return fmt.Errorf("failed to check project owner: %w", err)
+	}
+	if !isOwner(ownerID, userID) {
+		if !canView(visibility) {
+			return projectNotFound(projectID)
+		}
+		return fmt.Errorf("%w: project %d belongs to another user", ErrForbidden, projectID)
 	}
 	return nil
 }
+
+// authorizeMemberOwner is authorizeOwner for the team member's project. A member the user
+// can't see is reported as ErrTeamMemberNotFound.
+func (s *ProjectService) authorizeMemberOwner(ctx context.Context, memberID, userID int) error {
+	projectID, err := s.model.GetTeamMemberProjectIDContext(ctx, memberID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return teamMemberNotFound(memberID)
+		}
+		return fmt.Errorf("failed to check team member: %w", err)
+	}
+
+	err = s.authorizeOwner(ctx, projectID, userID)
+	if errors.Is(err, ErrProjectNotFound) {
+		return teamMemberNotFound(memberID)
+	}
+	return err
+}
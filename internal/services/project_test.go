@@ -0,0 +1,37 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/tarsuniversecentral/project-module/config"
+	"github.com/tarsuniversecentral/project-module/internal/dto"
+	"github.com/tarsuniversecentral/project-module/internal/models"
+	"github.com/tarsuniversecentral/project-module/internal/testdb"
+)
+
+func TestSubscribeEventsToPrivateProjectRequiresOwner(t *testing.T) {
+	db, tables, dialect := testdb.Open(t)
+	model := models.NewProjectModel(db, nil, tables, dialect, 0, 0)
+	t.Cleanup(func() { model.Close() })
+	s := NewProjectService(model, &config.Config{EventMaxSubscribers: 10})
+	ctx := context.Background()
+
+	p := &dto.Project{Title: "Private", Currency: "USD", Visibility: dto.VisibilityPrivate, OwnerID: 7}
+	if err := model.CreateProjectTxContext(ctx, p, ""); err != nil {
+		t.Fatalf("CreateProjectTxContext: %v", err)
+	}
+
+	for _, userID := range []int{0, 8} {
+		if _, err := s.SubscribeEvents(ctx, p.ID, userID); !errors.Is(err, ErrProjectNotFound) {
+			t.Errorf("SubscribeEvents as user %d = %v, want ErrProjectNotFound", userID, err)
+		}
+	}
+
+	sub, err := s.SubscribeEvents(ctx, p.ID, p.OwnerID)
+	if err != nil {
+		t.Fatalf("SubscribeEvents as owner: %v", err)
+	}
+	sub.Close()
+}
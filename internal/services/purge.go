@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/tarsuniversecentral/project-module/internal/models"
+	"github.com/tarsuniversecentral/project-module/pkg/storage"
+)
+
+// OrphanPurger periodically deletes stored files that no project references, such as the
+// uploads of a project that failed to be created and files whose cleanup failed. Files are
+// saved before their project row is inserted, so only files older than the grace period
+// are considered.
+type OrphanPurger struct {
+	model   *models.ProjectModel
+	storage storage.Storage
+	grace   time.Duration
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewOrphanPurger(model *models.ProjectModel, store storage.Storage, grace time.Duration) *OrphanPurger {
+	return &OrphanPurger{
+		model:   model,
+		storage: store,
+		grace:   grace,
+		cancel:  func() {},
+	}
+}
+
+// Start purges every interval in the background until Close is called.
+func (p *OrphanPurger) Start(interval time.Duration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+
+	p.wg.Add(1)
+	go p.loop(ctx, interval)
+}
+
+// Close stops the background purge, abandoning a run in progress rather than holding up
+// shutdown. It is a no-op if the purge was never started.
+func (p *OrphanPurger) Close() {
+	p.cancel()
+	p.wg.Wait()
+}
+
+func (p *OrphanPurger) loop(ctx context.Context, interval time.Duration) {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			runCtx, cancel := context.WithTimeout(ctx, interval)
+			removed, err := p.Purge(runCtx)
+			cancel()
+			if err != nil {
+				log.Printf("Orphan purge stopped after removing %d files: %v", removed, err)
+			} else if removed > 0 {
+				log.Printf("Orphan purge removed %d files", removed)
+			}
+		}
+	}
+}
+
+// Purge deletes the unreferenced files older than the grace period and returns how many
+// it removed. A file that fails to delete is logged and left for the next run.
+func (p *OrphanPurger) Purge(ctx context.Context) (int, error) {
+	cutoff := time.Now().Add(-p.grace)
+	removed := 0
+
+	for _, dir := range uploadDirs {
+		files, err := p.storage.List(dir)
+		if err != nil {
+			return removed, fmt.Errorf("listing %s: %w", dir, err)
+		}
+
+		var candidates []string
+		for _, f := range files {
+			if f.ModTime.Before(cutoff) {
+				candidates = append(candidates, f.Name)
+			}
+		}
+		if len(candidates) == 0 {
+			continue
+		}
+
+		referenced, err := p.model.ReferencedFilesContext(ctx, candidates)
+		if err != nil {
+			return removed, fmt.Errorf("checking references to %s: %w", dir, err)
+		}
+
+		for _, name := range candidates {
+			if referenced[name] {
+				continue
+			}
+			if err := ctx.Err(); err != nil {
+				return removed, err
+			}
+			if err := p.storage.Delete(dir, name); err != nil && !errors.Is(err, os.ErrNotExist) {
+				log.Printf("Orphan purge failed to delete %s/%s: %v", dir, name, err)
+				continue
+			}
+			log.Printf("Orphan purge deleted unreferenced file %s/%s", dir, name)
+			removed++
+		}
+	}
+	return removed, nil
+}
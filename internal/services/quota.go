@@ -0,0 +1,102 @@
+package services
+
+import (
+	"sync"
+	"time"
+)
+
+// UploadQuota tracks uploaded bytes per client over a rolling time window.
+// Check and Record are separate, so concurrent uploads from one client can
+// overshoot the limit slightly; it's meant to stop sustained abuse, not to be exact.
+type UploadQuota struct {
+	limit  int64
+	window time.Duration
+
+	mu        sync.Mutex
+	usage     map[string][]quotaEntry
+	lastSweep time.Time
+}
+
+type quotaEntry struct {
+	at    time.Time
+	bytes int64
+}
+
+// NewUploadQuota returns a quota allowing limit bytes per client within window.
+func NewUploadQuota(limit int64, window time.Duration) *UploadQuota {
+	return &UploadQuota{
+		limit:     limit,
+		window:    window,
+		usage:     make(map[string][]quotaEntry),
+		lastSweep: time.Now(),
+	}
+}
+
+// Check reports whether the client may upload n more bytes. When it may not, it also
+// returns how long until enough earlier uploads age out of the window.
+func (q *UploadQuota) Check(client string, n int64) (bool, time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+	entries := q.prune(client, now)
+
+	var used int64
+	for _, e := range entries {
+		used += e.bytes
+	}
+	if used+n <= q.limit {
+		return true, 0
+	}
+
+	// A single upload larger than the whole quota can never fit.
+	if n > q.limit {
+		return false, q.window
+	}
+
+	// Walk the entries oldest-first until enough bytes have expired.
+	for _, e := range entries {
+		used -= e.bytes
+		if used+n <= q.limit {
+			return false, e.at.Add(q.window).Sub(now)
+		}
+	}
+	return false, q.window
+}
+
+// Record accounts n bytes uploaded by the client.
+func (q *UploadQuota) Record(client string, n int64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+	q.usage[client] = append(q.prune(client, now), quotaEntry{at: now, bytes: n})
+
+	// Periodically drop clients that have been idle for a whole window.
+	if now.Sub(q.lastSweep) > q.window {
+		for key := range q.usage {
+			if len(q.prune(key, now)) == 0 {
+				delete(q.usage, key)
+			}
+		}
+		q.lastSweep = now
+	}
+}
+
+// prune drops the client's entries that fell out of the window. The caller must hold q.mu.
+func (q *UploadQuota) prune(client string, now time.Time) []quotaEntry {
+	entries := q.usage[client]
+	cutoff := now.Add(-q.window)
+
+	i := 0
+	for i < len(entries) && !entries[i].at.After(cutoff) {
+		i++
+	}
+	entries = entries[i:]
+	if len(entries) == 0 {
+		delete(q.usage, client)
+		return nil
+	}
+	q.usage[client] = entries
+	return entries
+}
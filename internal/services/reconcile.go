@@ -0,0 +1,72 @@
+package services
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/tarsuniversecentral/project-module/internal/models"
+)
+
+// CountReconciler periodically corrects the denormalized counts on projects against the
+// rows they count, fixing drift left by failed or concurrent updates. Only like_count is
+// reconciled so far: comment_count and follower_count will be once comments and followers
+// are recorded in tables of their own.
+type CountReconciler struct {
+	model *models.ProjectModel
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewCountReconciler(model *models.ProjectModel) *CountReconciler {
+	return &CountReconciler{
+		model:  model,
+		cancel: func() {},
+	}
+}
+
+// Start reconciles every interval in the background until Close is called.
+func (c *CountReconciler) Start(interval time.Duration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+
+	c.wg.Add(1)
+	go c.loop(ctx, interval)
+}
+
+// Close stops the background reconciliation, abandoning a run in progress rather than
+// holding up shutdown. It is a no-op if it was never started.
+func (c *CountReconciler) Close() {
+	c.cancel()
+	c.wg.Wait()
+}
+
+func (c *CountReconciler) loop(ctx context.Context, interval time.Duration) {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			runCtx, cancel := context.WithTimeout(ctx, interval)
+			corrected, err := c.Reconcile(runCtx)
+			cancel()
+			if err != nil {
+				log.Printf("Count reconciliation failed: %v", err)
+			} else if corrected > 0 {
+				log.Printf("Count reconciliation corrected %d projects", corrected)
+			}
+		}
+	}
+}
+
+// Reconcile corrects the drifted counts now and returns how many projects it changed.
+func (c *CountReconciler) Reconcile(ctx context.Context) (int64, error) {
+	return c.model.ReconcileLikeCountsContext(ctx)
+}
@@ -0,0 +1,74 @@
+// Package testdb opens the MySQL database that database tests run against.
+package testdb
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/tarsuniversecentral/project-module/pkg/database"
+	"github.com/tarsuniversecentral/project-module/pkg/database/migration"
+)
+
+// DSNEnv names the MySQL DSN the database tests run against. They are skipped when it is
+// unset. The DSN must include parseTime=true.
+const DSNEnv = "TEST_MYSQL_DSN"
+
+// Open returns a connection to the test database with freshly migrated tables, along with
+// the namer and dialect queries must go through. The tables carry a prefix unique to the
+// test and are dropped when it ends, so tests can share a database without seeing each
+// other's rows. Cleanups registered after Open, such as closing a model's statements, run
+// before the tables are dropped.
+func Open(tb testing.TB) (*sql.DB, *database.TableNamer, *database.Dialect) {
+	tb.Helper()
+
+	dsn := os.Getenv(DSNEnv)
+	if dsn == "" {
+		tb.Skipf("%s not set; skipping database test", DSNEnv)
+	}
+
+	db, err := sql.Open(database.DriverMySQL, dsn)
+	if err != nil {
+		tb.Fatalf("open test database: %v", err)
+	}
+	// One connection, so the FOREIGN_KEY_CHECKS setting in cleanup applies to the drops.
+	db.SetMaxOpenConns(1)
+
+	dialect, err := database.NewDialect(database.DriverMySQL)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tables, err := database.NewTableNamer(fmt.Sprintf("t%d_", time.Now().UnixNano()))
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	tb.Cleanup(func() {
+		defer db.Close()
+		if _, err := db.Exec(`SET FOREIGN_KEY_CHECKS = 0`); err != nil {
+			tb.Errorf("cleanup: %v", err)
+			return
+		}
+		for _, table := range database.Tables {
+			if _, err := db.Exec(tables.Rewrite("DROP TABLE IF EXISTS " + table)); err != nil {
+				tb.Errorf("cleanup: drop %s: %v", table, err)
+			}
+		}
+	})
+
+	if err := migration.RunMigrations(db, migrationsDir(), database.Rewriter(tables, dialect)); err != nil {
+		tb.Fatalf("migrate test database: %v", err)
+	}
+
+	return db, tables, dialect
+}
+
+// migrationsDir locates the MySQL migrations from this file, so tests in any package find them.
+func migrationsDir() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "..", "pkg", "database", "migration", "migrations")
+}
@@ -7,51 +7,90 @@ import (
 	"time"
 
 	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
 	"github.com/tarsuniversecentral/project-module/config"
 	"github.com/tarsuniversecentral/project-module/pkg/database/migration"
 )
 
 // InitDatabase initializes the database connection, configures the connection pool,
-// verifies the connection, and runs migrations.
-func InitDatabase() (*sql.DB, error) {
-	// Load the configuration.
-	cfg, err := config.LoadConfig()
+// verifies the connection, and runs migrations with the tables named by tables.
+func InitDatabase(cfg *config.Config, tables *TableNamer, dialect *Dialect) (*sql.DB, error) {
+	db, err := OpenDatabase(cfg, dialect)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load config: %w", err)
+		return nil, err
 	}
 
-	// Build the MySQL connection string.
-	connectionString := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true",
-		cfg.DBUser,
-		cfg.DBPassword,
-		cfg.DBHost,
-		cfg.DBPort,
-		cfg.DBName,
-	)
+	// Run database migrations.
+	if err = migration.RunMigrations(db, cfg.MigrationsDir, Rewriter(tables, dialect)); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to run migrations: %w", err)
+	}
 
+	log.Println("Migrations applied successfully")
+	return db, nil
+}
+
+// OpenDatabase connects to the primary database, verifies the connection and configures
+// the connection pool, without running migrations.
+func OpenDatabase(cfg *config.Config, dialect *Dialect) (*sql.DB, error) {
 	// Open the database connection.
-	db, err := sql.Open("mysql", connectionString)
+	db, err := sql.Open(dialect.Driver(), dialect.DSN(cfg))
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
-	// Verify the database connection with a ping.
-	if err = db.Ping(); err != nil {
+	// Verify the database connection, waiting for it to come up if needed.
+	if err = pingWithRetry(db, cfg.DBConnectMaxAttempts, cfg.DBConnectRetryDelay); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
 	log.Println("Connected to database")
 
 	// Configure the database connection pool.
-	db.SetMaxIdleConns(10)                 // Maximum number of idle connections.
-	db.SetMaxOpenConns(100)                // Maximum number of open connections.
-	db.SetConnMaxLifetime(5 * time.Minute) // Maximum time a connection can be reused.
+	configurePool(db, cfg.DBPool)
 
-	// Run database migrations.
-	if err = migration.RunMigrations(db); err != nil {
-		return nil, fmt.Errorf("failed to run migrations: %w", err)
+	return db, nil
+}
+
+// maxConnectRetryDelay caps the wait between connection attempts.
+const maxConnectRetryDelay = 30 * time.Second
+
+// pingWithRetry pings db up to attempts times, doubling the wait after each failure
+// starting from baseDelay, and returns the last error if the database never answers.
+func pingWithRetry(db *sql.DB, attempts int, baseDelay time.Duration) error {
+	if attempts < 1 {
+		attempts = 1
 	}
 
-	log.Println("Migrations applied successfully")
-	return db, nil
+	delay := baseDelay
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err = db.Ping(); err == nil {
+			return nil
+		}
+		log.Printf("Database not reachable (attempt %d/%d): %v", attempt, attempts, err)
+		if attempt == attempts {
+			break
+		}
+
+		log.Printf("Retrying database connection in %s", delay)
+		time.Sleep(delay)
+		delay = min(delay*2, maxConnectRetryDelay)
+	}
+	return fmt.Errorf("giving up after %d attempt(s): %w", attempts, err)
+}
+
+// connPool is the part of *sql.DB that configurePool sets.
+type connPool interface {
+	SetMaxIdleConns(n int)
+	SetMaxOpenConns(n int)
+	SetConnMaxLifetime(d time.Duration)
+}
+
+// configurePool applies the connection pool settings shared by the primary and replicas.
+func configurePool(db connPool, pool config.PoolConfig) {
+	db.SetMaxIdleConns(pool.MaxIdleConns)       // Maximum number of idle connections.
+	db.SetMaxOpenConns(pool.MaxOpenConns)       // Maximum number of open connections.
+	db.SetConnMaxLifetime(pool.ConnMaxLifetime) // Maximum time a connection can be reused.
 }
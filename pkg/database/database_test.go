@@ -0,0 +1,41 @@
+package database
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/tarsuniversecentral/project-module/config"
+)
+
+// recordingPool records the settings configurePool applies.
+type recordingPool struct {
+	maxIdle, maxOpen int
+	maxLifetime      time.Duration
+}
+
+func (p *recordingPool) SetMaxIdleConns(n int)              { p.maxIdle = n }
+func (p *recordingPool) SetMaxOpenConns(n int)              { p.maxOpen = n }
+func (p *recordingPool) SetConnMaxLifetime(d time.Duration) { p.maxLifetime = d }
+
+func TestConfigurePool(t *testing.T) {
+	settings := config.PoolConfig{MaxIdleConns: 7, MaxOpenConns: 42, ConnMaxLifetime: 3 * time.Minute}
+
+	var got recordingPool
+	configurePool(&got, settings)
+	want := recordingPool{maxIdle: 7, maxOpen: 42, maxLifetime: 3 * time.Minute}
+	if got != want {
+		t.Errorf("configurePool applied %+v, want %+v", got, want)
+	}
+
+	// And on a real pool; sql.Open doesn't connect.
+	db, err := sql.Open(DriverMySQL, "user:pass@tcp(127.0.0.1:1)/none")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	configurePool(db, settings)
+	if n := db.Stats().MaxOpenConnections; n != 42 {
+		t.Errorf("MaxOpenConnections = %d, want 42", n)
+	}
+}
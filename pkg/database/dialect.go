@@ -0,0 +1,119 @@
+package database
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/tarsuniversecentral/project-module/config"
+)
+
+// Supported DB_DRIVER values.
+const (
+	DriverMySQL    = "mysql"
+	DriverPostgres = "postgres"
+)
+
+// Dialect adapts SQL written with MySQL's ? placeholders to the configured driver.
+// Queries in this repository use ? throughout; Postgres expects $1, $2, ...
+type Dialect struct {
+	driver string
+
+	// Queries are mostly constants, so rewrites are memoized.
+	cache sync.Map
+}
+
+// NewDialect returns the dialect for driver, which defaults to MySQL when empty.
+func NewDialect(driver string) (*Dialect, error) {
+	switch driver {
+	case "":
+		return &Dialect{driver: DriverMySQL}, nil
+	case DriverMySQL, DriverPostgres:
+		return &Dialect{driver: driver}, nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q: use %q or %q", driver, DriverMySQL, DriverPostgres)
+	}
+}
+
+// Driver returns the database/sql driver name.
+func (d *Dialect) Driver() string {
+	if d == nil {
+		return DriverMySQL
+	}
+	return d.driver
+}
+
+// IsPostgres reports whether queries run against Postgres.
+func (d *Dialect) IsPostgres() bool {
+	return d.Driver() == DriverPostgres
+}
+
+// Rebind returns query with its ? placeholders numbered for Postgres. Question marks
+// inside quoted strings and identifiers are left alone. MySQL queries are unchanged.
+func (d *Dialect) Rebind(query string) string {
+	if !d.IsPostgres() || !strings.Contains(query, "?") {
+		return query
+	}
+	if rebound, ok := d.cache.Load(query); ok {
+		return rebound.(string)
+	}
+
+	var (
+		b     strings.Builder
+		n     int
+		quote rune
+	)
+	b.Grow(len(query) + 8)
+	for _, r := range query {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '?':
+			n++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+
+	rebound := b.String()
+	d.cache.Store(query, rebound)
+	return rebound
+}
+
+// DSN returns the connection string for the primary database in the driver's format.
+func (d *Dialect) DSN(cfg *config.Config) string {
+	if d.IsPostgres() {
+		u := url.URL{
+			Scheme:   "postgres",
+			User:     url.UserPassword(cfg.DBUser, cfg.DBPassword),
+			Host:     cfg.DBHost + ":" + cfg.DBPort,
+			Path:     "/" + cfg.DBName,
+			RawQuery: url.Values{"sslmode": {cfg.DBSSLMode}}.Encode(),
+		}
+		return u.String()
+	}
+
+	return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true",
+		cfg.DBUser,
+		cfg.DBPassword,
+		cfg.DBHost,
+		cfg.DBPort,
+		cfg.DBName,
+	)
+}
+
+// Rewriter returns a function adapting SQL to this deployment's table names and driver,
+// for code such as migrations that takes a plain rewrite function.
+func Rewriter(tables *TableNamer, dialect *Dialect) func(string) string {
+	return func(query string) string {
+		return dialect.Rebind(tables.Rewrite(query))
+	}
+}
@@ -2,18 +2,55 @@ package migration
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+
+	"github.com/go-sql-driver/mysql"
 )
 
-func RunMigrations(db *sql.DB) error {
+const createMigrationsTable = `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		filename VARCHAR(255) NOT NULL PRIMARY KEY,
+		applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`
+
+// MySQL errors raised when a schema change is already in place. Databases migrated before
+// schema_migrations existed have no record of what ran, so while schema_migrations is
+// empty at startup a migration failing with one of these is recorded as applied instead of
+// aborting startup. Once anything is recorded they are errors like any other: the schema
+// has drifted from the migrations.
+var alreadyAppliedErrors = map[uint16]bool{
+	1050: true, // ER_TABLE_EXISTS_ERROR
+	1060: true, // ER_DUP_FIELDNAME
+	1061: true, // ER_DUP_KEYNAME
+}
+
+// RunMigrations applies the *_up.sql migrations in dir not yet recorded in schema_migrations,
+// in filename order, and records each one with the time it ran. Each statement is passed
+// through rewrite first, which names the tables for this deployment.
+func RunMigrations(db *sql.DB, dir string, rewrite func(string) string) error {
 
-	migrationDir := "./pkg/database/migration/migrations"
-	files, err := os.ReadDir(migrationDir)
+	if err := checkDir(dir); err != nil {
+		return err
+	}
+
+	if _, err := db.Exec(rewrite(createMigrationsTable)); err != nil {
+		return fmt.Errorf("error creating schema_migrations: %w", err)
+	}
+
+	applied, err := appliedMigrations(db, rewrite)
+	if err != nil {
+		return err
+	}
+	// Only a database with no migration history can be one set up before schema_migrations.
+	legacy := len(applied) == 0
+
+	files, err := os.ReadDir(dir)
 	if err != nil {
 		return err
 	}
@@ -28,7 +65,11 @@ func RunMigrations(db *sql.DB) error {
 	sort.Strings(migrations)
 
 	for _, migration := range migrations {
-		path := filepath.Join(migrationDir, migration)
+		if applied[migration] {
+			continue
+		}
+
+		path := filepath.Join(dir, migration)
 		content, err := os.ReadFile(path)
 		if err != nil {
 			return err
@@ -39,9 +80,17 @@ func RunMigrations(db *sql.DB) error {
 			return err
 		}
 
-		if _, err = tx.Exec(string(content)); err != nil {
+		if _, err = tx.Exec(rewrite(string(content))); err != nil {
+			if !legacy || !isAlreadyApplied(err) {
+				tx.Rollback()
+				return fmt.Errorf("error executing migration %s: %v", migration, err)
+			}
+			log.Printf("Migration %s is already in place (%v); recording it as applied", migration, err)
+		}
+
+		if _, err = tx.Exec(rewrite(`INSERT INTO schema_migrations (filename) VALUES (?)`), migration); err != nil {
 			tx.Rollback()
-			return fmt.Errorf("error executing migration %s: %v", migration, err)
+			return fmt.Errorf("error recording migration %s: %v", migration, err)
 		}
 
 		if err = tx.Commit(); err != nil {
@@ -53,3 +102,109 @@ func RunMigrations(db *sql.DB) error {
 
 	return nil
 }
+
+// RollbackLastMigration reverts the most recently applied migration by running its
+// _down.sql file and removing its schema_migrations record, and returns its filename.
+// It fails without changing anything when nothing has been applied or the down file is
+// missing. A down file holding only comments marks a migration with nothing to undo.
+func RollbackLastMigration(db *sql.DB, dir string, rewrite func(string) string) (string, error) {
+
+	if err := checkDir(dir); err != nil {
+		return "", err
+	}
+
+	var migration string
+	query := `SELECT filename FROM schema_migrations ORDER BY applied_at DESC, filename DESC LIMIT 1`
+	if err := db.QueryRow(rewrite(query)).Scan(&migration); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", errors.New("no applied migrations to roll back")
+		}
+		return "", fmt.Errorf("error reading schema_migrations: %w", err)
+	}
+
+	downFile := strings.TrimSuffix(migration, "_up.sql") + "_down.sql"
+	content, err := os.ReadFile(filepath.Join(dir, downFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("cannot roll back %s: %s does not exist", migration, downFile)
+		}
+		return "", err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return "", err
+	}
+
+	if statement := string(content); !isCommentOnly(statement) {
+		if _, err := tx.Exec(rewrite(statement)); err != nil {
+			tx.Rollback()
+			return "", fmt.Errorf("error executing rollback %s: %v", downFile, err)
+		}
+	}
+
+	if _, err := tx.Exec(rewrite(`DELETE FROM schema_migrations WHERE filename = ?`), migration); err != nil {
+		tx.Rollback()
+		return "", fmt.Errorf("error unrecording migration %s: %v", migration, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+
+	log.Printf("Rolled back migration: %s\n", migration)
+	return migration, nil
+}
+
+// checkDir fails with a descriptive error when the migrations directory is missing, which
+// usually means the binary runs from another working directory than MIGRATIONS_DIR assumes.
+func checkDir(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			abs, _ := filepath.Abs(dir)
+			return fmt.Errorf("migrations directory %q (%s) does not exist; set MIGRATIONS_DIR", dir, abs)
+		}
+		return fmt.Errorf("migrations directory %q: %w", dir, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("migrations directory %q is not a directory; set MIGRATIONS_DIR", dir)
+	}
+	return nil
+}
+
+// isCommentOnly reports whether the SQL holds nothing but blank lines and -- comments.
+func isCommentOnly(statement string) bool {
+	for _, line := range strings.Split(statement, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "--") {
+			return false
+		}
+	}
+	return true
+}
+
+// isAlreadyApplied reports whether err is one of alreadyAppliedErrors.
+func isAlreadyApplied(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && alreadyAppliedErrors[mysqlErr.Number]
+}
+
+// appliedMigrations returns the filenames recorded in schema_migrations.
+func appliedMigrations(db *sql.DB, rewrite func(string) string) (map[string]bool, error) {
+	rows, err := db.Query(rewrite(`SELECT filename FROM schema_migrations`))
+	if err != nil {
+		return nil, fmt.Errorf("error reading schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[string]bool)
+	for rows.Next() {
+		var filename string
+		if err := rows.Scan(&filename); err != nil {
+			return nil, fmt.Errorf("error reading schema_migrations: %w", err)
+		}
+		applied[filename] = true
+	}
+	return applied, rows.Err()
+}
@@ -0,0 +1,35 @@
+package migration_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tarsuniversecentral/project-module/internal/testdb"
+	"github.com/tarsuniversecentral/project-module/pkg/database"
+	"github.com/tarsuniversecentral/project-module/pkg/database/migration"
+)
+
+func TestRunMigrationsAdoptsExistingSchemaOnlyWithoutHistory(t *testing.T) {
+	db, tables, dialect := testdb.Open(t)
+	rewrite := database.Rewriter(tables, dialect)
+
+	// A migration whose change is already in place: projects exists.
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "9999_create_table_projects_up.sql"), []byte(`CREATE TABLE projects (id INT)`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// schema_migrations records the migrations testdb ran, so the schema has drifted.
+	if err := migration.RunMigrations(db, dir, rewrite); err == nil {
+		t.Fatal("RunMigrations adopted a conflicting migration into an existing history")
+	}
+
+	// Without any history the database predates schema_migrations and is adopted.
+	if _, err := db.Exec(rewrite(`DELETE FROM schema_migrations`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := migration.RunMigrations(db, dir, rewrite); err != nil {
+		t.Fatalf("RunMigrations on a database without history: %v", err)
+	}
+}
@@ -0,0 +1,5 @@
+ALTER TABLE projects
+    DROP COLUMN like_count,
+    DROP COLUMN comment_count,
+    DROP COLUMN view_count,
+    DROP COLUMN verified;
@@ -0,0 +1 @@
+ALTER TABLE projects ADD COLUMN visibility ENUM('public', 'unlisted', 'private') NOT NULL DEFAULT 'public';
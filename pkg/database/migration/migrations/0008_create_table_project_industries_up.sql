@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS project_industries (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    project_id INT NOT NULL,
+    industry VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uniq_project_industry (project_id, industry),
+    KEY idx_industry (industry),
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+);
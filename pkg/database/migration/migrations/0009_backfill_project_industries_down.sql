@@ -0,0 +1 @@
+-- The backfill only copies data into project_industries, which 0008's rollback drops.
@@ -0,0 +1,2 @@
+INSERT IGNORE INTO project_industries (project_id, industry)
+SELECT id, industry FROM projects WHERE industry IS NOT NULL AND industry <> '';
@@ -0,0 +1 @@
+ALTER TABLE project_pitch_decks DROP COLUMN preview_path;
@@ -0,0 +1 @@
+ALTER TABLE project_pitch_decks ADD COLUMN preview_path VARCHAR(255) NULL;
@@ -0,0 +1,6 @@
+UPDATE projects
+SET subtitle = NULLIF(subtitle, ''),
+    industry = NULLIF(industry, ''),
+    description = NULLIF(description, ''),
+    looking_for = NULLIF(looking_for, ''),
+    github_link = NULLIF(github_link, '');
@@ -0,0 +1 @@
+-- NULL and empty optional fields read the same, so there is nothing to undo.
@@ -0,0 +1,4 @@
+UPDATE team_members
+SET profile_url = NULLIF(profile_url, ''),
+    title = NULLIF(title, ''),
+    role = NULLIF(role, '');
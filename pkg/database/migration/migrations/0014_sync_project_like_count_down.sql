@@ -0,0 +1 @@
+-- like_count is derived from project_likes; the resync has nothing to undo.
@@ -0,0 +1,3 @@
+UPDATE projects
+SET like_count = (SELECT COUNT(*) FROM project_likes WHERE project_likes.project_id = projects.id),
+    updated_at = updated_at;
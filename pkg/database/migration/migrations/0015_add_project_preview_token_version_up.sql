@@ -0,0 +1 @@
+ALTER TABLE projects ADD COLUMN preview_token_version INT NOT NULL DEFAULT 0;
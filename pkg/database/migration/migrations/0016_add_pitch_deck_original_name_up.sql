@@ -0,0 +1 @@
+ALTER TABLE project_pitch_decks ADD COLUMN original_name VARCHAR(255) NULL;
@@ -0,0 +1 @@
+ALTER TABLE projects ADD COLUMN owner_id INT NULL, ADD KEY idx_owner_id (owner_id);
@@ -0,0 +1 @@
+ALTER TABLE team_members ADD COLUMN version INT NOT NULL DEFAULT 1;
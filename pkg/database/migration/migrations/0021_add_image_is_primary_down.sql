@@ -0,0 +1 @@
+ALTER TABLE project_images DROP COLUMN is_primary;
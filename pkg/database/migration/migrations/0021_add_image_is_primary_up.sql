@@ -0,0 +1 @@
+ALTER TABLE project_images ADD COLUMN is_primary BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS projects (
+    id SERIAL PRIMARY KEY,
+    title VARCHAR(255) NOT NULL,
+    subtitle VARCHAR(255),
+    industry VARCHAR(255),
+    description TEXT,
+    project_value DECIMAL(15,2),
+    looking_for VARCHAR(100),
+    github_link VARCHAR(255),
+    website_link VARCHAR(255),
+    like_count INT DEFAULT 0,
+    comment_count INT DEFAULT 0,
+    view_count INT DEFAULT 0,
+    verified BOOLEAN DEFAULT FALSE,
+    visibility VARCHAR(16) NOT NULL DEFAULT 'public' CHECK (visibility IN ('public', 'unlisted', 'private')),
+    preview_token_version INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS project_pitch_decks (
+    id SERIAL PRIMARY KEY,
+    project_id INT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    file_path VARCHAR(255) NOT NULL,
+    preview_path VARCHAR(255) NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1 @@
+-- The index is dropped with project_industries by 0005's rollback.
@@ -0,0 +1 @@
+ALTER TABLE project_images ADD COLUMN original_name VARCHAR(255) NULL;
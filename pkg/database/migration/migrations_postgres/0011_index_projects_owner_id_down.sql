@@ -0,0 +1 @@
+-- The index is dropped with owner_id by 0010's rollback.
@@ -0,0 +1,2 @@
+-- Unnamed so Postgres derives the index name from the (possibly prefixed) table name.
+CREATE INDEX ON projects (owner_id);
@@ -0,0 +1 @@
+ALTER TABLE projects ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'USD';
@@ -0,0 +1 @@
+-- The index is dropped with is_primary by 0014's rollback.
@@ -0,0 +1,3 @@
+-- Unnamed so Postgres derives the index name from the (possibly prefixed) table name.
+-- At most one primary image per project; MySQL relies on SetPrimaryImageTx alone.
+CREATE UNIQUE INDEX ON project_images (project_id) WHERE is_primary;
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS team_member_reassignments (
+    id SERIAL PRIMARY KEY,
+    team_member_id INT NOT NULL,
+    from_project_id INT NOT NULL,
+    to_project_id INT NOT NULL,
+    reassigned_by INT NOT NULL,
+    reassigned_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
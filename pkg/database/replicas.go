@@ -0,0 +1,123 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/tarsuniversecentral/project-module/config"
+)
+
+// ReplicaSet routes read queries across read replicas in round-robin order.
+// Replicas that fail a health check are skipped until they recover, and reads
+// fall back to the primary when no replica is healthy.
+type ReplicaSet struct {
+	primary  *sql.DB
+	replicas []*replica
+	next     atomic.Uint64
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+type replica struct {
+	name    string
+	db      *sql.DB
+	healthy atomic.Bool
+}
+
+// OpenReplicaSet opens a connection pool per replica DSN, in the driver's format, and starts a background
+// health check running every interval. An empty dsns list yields a set that
+// always reads from the primary.
+func OpenReplicaSet(primary *sql.DB, driver string, dsns []string, pool config.PoolConfig, interval time.Duration) (*ReplicaSet, error) {
+	rs := &ReplicaSet{primary: primary, stop: make(chan struct{})}
+
+	for i, dsn := range dsns {
+		db, err := sql.Open(driver, dsn)
+		if err != nil {
+			rs.Close()
+			return nil, fmt.Errorf("failed to open read replica %d: %w", i, err)
+		}
+		configurePool(db, pool)
+
+		r := &replica{name: fmt.Sprintf("replica-%d", i), db: db}
+		// An unreachable replica doesn't block startup; it's retried by the health check.
+		if err := db.Ping(); err != nil {
+			log.Printf("Read replica %s unreachable, falling back to primary: %v", r.name, err)
+		} else {
+			r.healthy.Store(true)
+		}
+		rs.replicas = append(rs.replicas, r)
+	}
+
+	if len(rs.replicas) > 0 && interval > 0 {
+		rs.wg.Add(1)
+		go rs.healthLoop(interval)
+	}
+
+	return rs, nil
+}
+
+// Reader returns the connection pool to use for the next read query.
+func (rs *ReplicaSet) Reader() *sql.DB {
+	n := len(rs.replicas)
+	if n == 0 {
+		return rs.primary
+	}
+
+	start := rs.next.Add(1)
+	for i := 0; i < n; i++ {
+		r := rs.replicas[(start+uint64(i))%uint64(n)]
+		if r.healthy.Load() {
+			return r.db
+		}
+	}
+	return rs.primary
+}
+
+// Close stops the health check and closes the replica pools. The primary is left open.
+func (rs *ReplicaSet) Close() error {
+	close(rs.stop)
+	rs.wg.Wait()
+
+	var firstErr error
+	for _, r := range rs.replicas {
+		if err := r.db.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (rs *ReplicaSet) healthLoop(interval time.Duration) {
+	defer rs.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-rs.stop:
+			return
+		case <-ticker.C:
+			for _, r := range rs.replicas {
+				ctx, cancel := context.WithTimeout(context.Background(), interval)
+				err := r.db.PingContext(ctx)
+				cancel()
+
+				healthy := err == nil
+				if was := r.healthy.Swap(healthy); was != healthy {
+					if healthy {
+						log.Printf("Read replica %s recovered", r.name)
+					} else {
+						log.Printf("Read replica %s unhealthy, falling back: %v", r.name, err)
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package database
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Tables lists every table the service owns. Queries and migrations name them without
+// a prefix; TableNamer adds the configured one.
+var Tables = []string{
+	"projects",
+	"team_members",
+	"project_pitch_decks",
+	"project_images",
+	"project_industries",
+	"project_likes",
+	"team_member_reassignments",
+	"schema_migrations",
+}
+
+// maxTablePrefixLength keeps prefixed names within MySQL's 64-character identifier limit.
+const maxTablePrefixLength = 32
+
+var tablePrefixPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+// TableNamer rewrites the table names in SQL to carry a prefix, so several environments
+// can share one database. Only whole identifiers matching Tables are rewritten; columns
+// such as project_id are left alone.
+type TableNamer struct {
+	prefix  string
+	pattern *regexp.Regexp
+
+	// Queries are mostly constants, so rewrites are memoized.
+	cache sync.Map
+}
+
+// NewTableNamer returns a namer for prefix, which may be empty. The prefix must start with
+// a letter and contain only letters, digits and underscores, so it is safe to splice into SQL.
+func NewTableNamer(prefix string) (*TableNamer, error) {
+	if prefix == "" {
+		return &TableNamer{}, nil
+	}
+	if len(prefix) > maxTablePrefixLength || !tablePrefixPattern.MatchString(prefix) {
+		return nil, fmt.Errorf("invalid table prefix %q: use up to %d letters, digits or underscores, starting with a letter", prefix, maxTablePrefixLength)
+	}
+
+	return &TableNamer{
+		prefix:  prefix,
+		pattern: regexp.MustCompile(`\b(` + strings.Join(Tables, "|") + `)\b`),
+	}, nil
+}
+
+// Rewrite returns query with every table name prefixed.
+func (n *TableNamer) Rewrite(query string) string {
+	if n == nil || n.prefix == "" {
+		return query
+	}
+	if rewritten, ok := n.cache.Load(query); ok {
+		return rewritten.(string)
+	}
+
+	rewritten := n.pattern.ReplaceAllString(query, n.prefix+"$1")
+	n.cache.Store(query, rewritten)
+	return rewritten
+}
@@ -0,0 +1,145 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// LocalStorage keeps files on the local filesystem, one subdirectory per category,
+// under a root directory.
+type LocalStorage struct {
+	root string
+}
+
+// NewLocalStorage returns a storage keeping files under root. An empty root is the
+// working directory.
+func NewLocalStorage(root string) *LocalStorage {
+	return &LocalStorage{root: root}
+}
+
+func (s *LocalStorage) Save(ctx context.Context, dir, name string, r io.Reader) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	dstPath, err := s.path(dir, name)
+	if err != nil {
+		return err
+	}
+
+	if err := createDirIfNotExist(s.dir(dir)); err != nil {
+		return fmt.Errorf("creating directory %s: %w", s.dir(dir), err)
+	}
+
+	dst, err := os.Create(dstPath)
+	if err != nil {
+		return fmt.Errorf("creating destination file: %w", noSpace(err))
+	}
+
+	_, err = io.Copy(dst, &ctxReader{ctx: ctx, r: r})
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		// Don't leave a partial file behind.
+		os.Remove(dstPath)
+		return fmt.Errorf("copying file: %w", noSpace(err))
+	}
+	return nil
+}
+
+// Open returns an *os.File, so callers can Stat it for the size.
+func (s *LocalStorage) Open(dir, name string) (io.ReadCloser, error) {
+	p, err := s.path(dir, name)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(p)
+}
+
+func (s *LocalStorage) Delete(dir, name string) error {
+	p, err := s.path(dir, name)
+	if err != nil {
+		return err
+	}
+	return os.Remove(p)
+}
+
+func (s *LocalStorage) List(dir string) ([]FileInfo, error) {
+	entries, err := os.ReadDir(s.dir(dir))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	files := make([]FileInfo, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			// Removed since the directory was read.
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		files = append(files, FileInfo{Name: entry.Name(), ModTime: info.ModTime()})
+	}
+	return files, nil
+}
+
+// ctxReader stops a copy once its context is done, e.g. when the server shuts down mid-upload.
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (cr *ctxReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cr.r.Read(p)
+}
+
+// noSpace wraps err with ErrNoSpace when the filesystem is full.
+func noSpace(err error) error {
+	if errors.Is(err, syscall.ENOSPC) {
+		return fmt.Errorf("%w: %w", ErrNoSpace, err)
+	}
+	return err
+}
+
+// dir returns the filesystem path of the category directory dir.
+func (s *LocalStorage) dir(dir string) string {
+	return filepath.Join(s.root, dir)
+}
+
+// path joins dir and name under the root. name must be a single path element, so names
+// such as "../x" are refused rather than stripped; as a last line of defense, dir must stay
+// inside the root and the cleaned path inside dir, which rules out "." and "..".
+func (s *LocalStorage) path(dir, name string) (string, error) {
+	dirPath := s.dir(dir)
+	p := filepath.Join(dirPath, name)
+	if !filepath.IsLocal(dir) || name != filepath.Base(name) || !strings.HasPrefix(p, dirPath+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: %q in %q", ErrInvalidPath, name, dir)
+	}
+	return p, nil
+}
+
+// Function to create directories if they don't exist
+func createDirIfNotExist(dir string) error {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return os.MkdirAll(dir, 0755)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLocalStorageRejectsTraversal(t *testing.T) {
+	tests := []struct {
+		dir  string
+		name string
+	}{
+		{"pdfs", "../../etc/passwd"},
+		{"pdfs", "../images/x.png"},
+		{"pdfs", "/etc/passwd"},
+		{"pdfs", "sub/x.pdf"},
+		{"pdfs", "x.pdf/"},
+		{"pdfs", ".."},
+		{"pdfs", "."},
+		{"pdfs", ""},
+		{"..", "x.pdf"},
+		{"../pdfs", "x.pdf"},
+		{"/tmp", "x.pdf"},
+		{"", "x.pdf"},
+	}
+
+	s := NewLocalStorage(t.TempDir())
+	for _, tt := range tests {
+		t.Run(tt.dir+"|"+tt.name, func(t *testing.T) {
+			if err := s.Save(context.Background(), tt.dir, tt.name, strings.NewReader("data")); !errors.Is(err, ErrInvalidPath) {
+				t.Errorf("Save error = %v, want ErrInvalidPath", err)
+			}
+			if f, err := s.Open(tt.dir, tt.name); !errors.Is(err, ErrInvalidPath) {
+				if err == nil {
+					f.Close()
+				}
+				t.Errorf("Open error = %v, want ErrInvalidPath", err)
+			}
+			if err := s.Delete(tt.dir, tt.name); !errors.Is(err, ErrInvalidPath) {
+				t.Errorf("Delete error = %v, want ErrInvalidPath", err)
+			}
+		})
+	}
+}
+
+func TestLocalStorageRoundTrip(t *testing.T) {
+	s := NewLocalStorage(t.TempDir())
+	if err := s.Save(context.Background(), "pdfs", "x.pdf", strings.NewReader("data")); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	f, err := s.Open("pdfs", "x.pdf")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	f.Close()
+	if err := s.Delete("pdfs", "x.pdf"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+}
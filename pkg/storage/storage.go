@@ -0,0 +1,40 @@
+// Package storage abstracts where uploaded files are kept.
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+var (
+	// ErrNoSpace is wrapped by Save errors caused by the backend running out of space.
+	ErrNoSpace = errors.New("no space left in storage")
+	// ErrInvalidPath is wrapped by errors for a directory or name that would resolve to a
+	// path outside the storage root.
+	ErrInvalidPath = errors.New("path outside storage root")
+)
+
+// Storage stores uploaded files by directory and name. Directories group files by
+// category ("pdfs", "images") and names are expected to be sanitized and unique.
+//
+// Implementations must be safe for concurrent use. Open and Delete return an error
+// matching os.ErrNotExist (via errors.Is) when the file doesn't exist.
+type Storage interface {
+	// Save writes the content of r as dir/name, replacing any existing file. It stops
+	// when ctx is done and, like any failed save, leaves no partial file behind.
+	Save(ctx context.Context, dir, name string, r io.Reader) error
+	// Open returns the content of dir/name. The caller must close it.
+	Open(dir, name string) (io.ReadCloser, error)
+	// Delete removes dir/name.
+	Delete(dir, name string) error
+	// List returns the files in dir. A directory that doesn't exist yet has no files.
+	List(dir string) ([]FileInfo, error)
+}
+
+// FileInfo describes a stored file.
+type FileInfo struct {
+	Name    string
+	ModTime time.Time
+}
@@ -0,0 +1,53 @@
+// Package tracing is the seam spans are started through. It does nothing until a Tracer
+// is installed with SetTracer: to export spans, e.g. over OTLP, implement Tracer on top of
+// the exporter's SDK and install it at startup.
+package tracing
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Tracer starts spans.
+type Tracer interface {
+	// Start begins a span named name, as a child of the span in ctx if there is one, and
+	// returns a context carrying the new span.
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is one timed operation. Implementations must be safe for concurrent use.
+type Span interface {
+	// SetAttribute annotates the span.
+	SetAttribute(key string, value any)
+	// RecordError marks the span as failed with err. A nil err is ignored.
+	RecordError(err error)
+	// End finishes the span. Nothing may be recorded on it afterwards.
+	End()
+}
+
+var tracer atomic.Pointer[Tracer]
+
+// SetTracer installs t for all spans started afterwards. A nil t restores the default,
+// which records nothing.
+func SetTracer(t Tracer) {
+	if t == nil {
+		tracer.Store(nil)
+		return
+	}
+	tracer.Store(&t)
+}
+
+// Start begins a span with the installed Tracer. Without one it returns ctx unchanged and
+// a span that records nothing, so callers can always defer span.End().
+func Start(ctx context.Context, name string) (context.Context, Span) {
+	if t := tracer.Load(); t != nil {
+		return (*t).Start(ctx, name)
+	}
+	return ctx, noopSpan{}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttribute(string, any) {}
+func (noopSpan) RecordError(error)        {}
+func (noopSpan) End()                     {}
@@ -0,0 +1,36 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+)
+
+type recordingTracer struct{ names []string }
+
+func (t *recordingTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	t.names = append(t.names, name)
+	return ctx, noopSpan{}
+}
+
+func TestStart(t *testing.T) {
+	ctx := context.Background()
+	if got, span := Start(ctx, "unconfigured"); got != ctx || span == nil {
+		t.Errorf("Start without a tracer = (%v, %v), want ctx unchanged and a span", got, span)
+	}
+
+	rec := &recordingTracer{}
+	SetTracer(rec)
+	t.Cleanup(func() { SetTracer(nil) })
+
+	_, span := Start(ctx, "configured")
+	span.End()
+	if len(rec.names) != 1 || rec.names[0] != "configured" {
+		t.Errorf("spans started = %q, want [configured]", rec.names)
+	}
+
+	SetTracer(nil)
+	Start(ctx, "reset")
+	if len(rec.names) != 1 {
+		t.Errorf("span started after SetTracer(nil): %q", rec.names)
+	}
+}
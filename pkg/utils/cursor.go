@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCursor is returned when a pagination cursor is malformed or its signature doesn't match.
+// Handlers should map it to 400 Bad Request.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor identifies a position in a list ordered by (created_at, id).
+type Cursor struct {
+	CreatedAt time.Time
+	ID        int
+}
+
+// cursorKey signs cursor payloads. It defaults to a random per-process key, so cursors
+// issued before a restart stop validating unless a fixed key is configured.
+var cursorKey = randomKey()
+
+func randomKey() []byte {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(fmt.Sprintf("generating cursor signing key: %v", err))
+	}
+	return key
+}
+
+// SetCursorSigningKey replaces the key used to sign cursors. An empty key keeps the random default.
+// It must be called during startup, before any cursor is encoded or decoded.
+func SetCursorSigningKey(key []byte) {
+	if len(key) > 0 {
+		cursorKey = key
+	}
+}
+
+// EncodeCursor serializes the cursor into an opaque, URL-safe token of the form payload.signature.
+func EncodeCursor(c Cursor) string {
+	payload := strconv.FormatInt(c.CreatedAt.UnixNano(), 10) + ":" + strconv.Itoa(c.ID)
+	encoded := base64.RawURLEncoding.EncodeToString([]byte(payload))
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(signCursor(encoded))
+}
+
+// DecodeCursor verifies the token's signature and parses it back into a Cursor.
+// Any malformed or tampered token yields ErrInvalidCursor.
+func DecodeCursor(token string) (Cursor, error) {
+	encoded, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	gotSig, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(gotSig, signCursor(encoded)) {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	nanosStr, idStr, ok := strings.Cut(string(payload), ":")
+	if !ok {
+		return Cursor{}, ErrInvalidCursor
+	}
+	nanos, err := strconv.ParseInt(nanosStr, 10, 64)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	return Cursor{CreatedAt: time.Unix(0, nanos).UTC(), ID: id}, nil
+}
+
+func signCursor(payload string) []byte {
+	mac := hmac.New(sha256.New, cursorKey)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
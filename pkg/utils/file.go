@@ -1,13 +1,51 @@
 package utils
 
 import (
+	"path"
 	"path/filepath"
+	"strings"
+	"unicode"
 
 	"github.com/google/uuid"
 )
 
 // GenerateUniqueFilename generates a unique filename using a UUID and preserves the original file extension.
 func GenerateUniqueFilename(original string) string {
-	ext := filepath.Ext(original)
+	ext := filepath.Ext(SanitizeFilename(original))
 	return uuid.New().String() + ext
 }
+
+// HasPathElements reports whether name contains a path separator (slash or backslash), a
+// ".." sequence or a null byte, none of which a stored file's name ever has.
+func HasPathElements(name string) bool {
+	return strings.ContainsAny(name, "/\\\x00") || strings.Contains(name, "..")
+}
+
+// SanitizeFilename reduces an untrusted filename to a bare base name that is safe
+// to join onto a storage directory or to place in a response header.
+// It drops directory components, traversal sequences, null bytes, control
+// characters and double quotes. An empty string is returned when nothing usable remains.
+func SanitizeFilename(name string) string {
+	// Strip null bytes, control characters and quotes before anything else so they
+	// can't be used to hide separators or break out of a quoted header value.
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+
+	// Treat backslashes as separators so Windows-style paths are stripped too.
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+
+	// Remove any remaining traversal sequences and surrounding dots/spaces.
+	for strings.Contains(name, "..") {
+		name = strings.ReplaceAll(name, "..", "")
+	}
+	name = strings.Trim(name, " .")
+
+	if name == "" || name == "/" {
+		return ""
+	}
+	return name
+}
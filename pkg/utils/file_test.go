@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHasPathElements(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"report.pdf", false},
+		{"3f2b9c1e-7d4a-4c8e-9f0a-1b2c3d4e5f60.png", false},
+		{"name with spaces.jpg", false},
+		{"", false},
+		{"../x", true},
+		{"..", true},
+		{"a/b", true},
+		{"/etc/passwd", true},
+		{`..\x`, true},
+		{`a\b`, true},
+		{"x\x00.png", true},
+		{"a..b", true},
+		// Route variables arrive decoded, so percent escapes are literal characters here.
+		{"%2e%2e%2fx", false},
+		{"..%2fx", true},
+	}
+	for _, tt := range tests {
+		if got := HasPathElements(tt.name); got != tt.want {
+			t.Errorf("HasPathElements(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func FuzzSanitizeFilename(f *testing.F) {
+	for _, seed := range []string{
+		"report.pdf",
+		"../../etc/passwd",
+		`..\..\windows\win.ini`,
+		"a/b/c.png",
+		"....//x",
+		". .",
+		"x\x00.png",
+		"\"quoted\".pdf",
+		"%2e%2e%2fx",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, name string) {
+		got := SanitizeFilename(name)
+		if strings.ContainsAny(got, `/\`) {
+			t.Errorf("SanitizeFilename(%q) = %q contains a path separator", name, got)
+		}
+		if strings.Contains(got, "..") {
+			t.Errorf("SanitizeFilename(%q) = %q contains ..", name, got)
+		}
+		if HasPathElements(got) {
+			t.Errorf("SanitizeFilename(%q) = %q has path elements", name, got)
+		}
+	})
+}
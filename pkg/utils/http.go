@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Error codes sent in error responses. Clients should branch on the code; messages are
+// for humans and may change.
+const (
+	ErrCodeBadRequest           = "bad_request"
+	ErrCodeValidation           = "validation_failed"
+	ErrCodeUnauthorized         = "unauthorized"
+	ErrCodeForbidden            = "forbidden"
+	ErrCodeNotFound             = "not_found"
+	ErrCodeConflict             = "conflict"
+	ErrCodePreconditionRequired = "precondition_required"
+	ErrCodeMethodNotAllowed     = "method_not_allowed"
+	ErrCodePayloadTooLarge      = "payload_too_large"
+	ErrCodeUnsupportedMediaType = "unsupported_media_type"
+	ErrCodeRateLimited          = "rate_limited"
+	ErrCodeQuotaExceeded        = "quota_exceeded"
+	ErrCodeInsufficientStorage  = "insufficient_storage"
+	ErrCodeUpstream             = "upstream_error"
+	ErrCodeUnavailable          = "unavailable"
+	ErrCodeInternal             = "internal_error"
+)
+
+// ErrorResponse is the body of every error response: {"error": {"message": ..., "code": ...}}.
+type ErrorResponse struct {
+	Error ErrorDetail `json:"error"`
+}
+
+// ErrorDetail describes what went wrong.
+type ErrorDetail struct {
+	Message string `json:"message"`
+	Code    string `json:"code"`
+}
+
+// WriteJSONError writes an error response with an ErrorResponse JSON body.
+func WriteJSONError(w http.ResponseWriter, status int, message, code string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ErrorResponse{Error: ErrorDetail{Message: message, Code: code}})
+}
+
+// WriteRetryableError writes a throttling or unavailability error (typically 429 or 503)
+// and always includes a Retry-After header so well-behaved clients can back off.
+// The delay is rounded up to whole seconds with a minimum of one second.
+func WriteRetryableError(w http.ResponseWriter, status int, message, code string, retryAfter time.Duration) {
+	SetRetryAfter(w, retryAfter)
+	WriteJSONError(w, status, message, code)
+}
+
+// SetRetryAfter sets the Retry-After header in seconds, rounding up with a minimum of one second.
+func SetRetryAfter(w http.ResponseWriter, retryAfter time.Duration) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+}
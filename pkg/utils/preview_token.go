@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidPreviewToken is returned when a preview token is malformed or its signature doesn't match.
+var ErrInvalidPreviewToken = errors.New("invalid preview token")
+
+// PreviewToken grants read access to one project until it expires. Version must match the
+// project's current preview token version, so bumping that version revokes every token
+// issued before.
+type PreviewToken struct {
+	ProjectID int
+	Version   int
+	ExpiresAt time.Time
+}
+
+// previewTokenKey signs preview tokens. Like cursorKey it defaults to a random per-process
+// key, so tokens stop validating on restart unless a fixed key is configured.
+var previewTokenKey = randomKey()
+
+// SetPreviewTokenSigningKey replaces the key used to sign preview tokens. An empty key keeps
+// the random default. It must be called during startup, before any token is issued or checked.
+func SetPreviewTokenSigningKey(key []byte) {
+	if len(key) > 0 {
+		previewTokenKey = key
+	}
+}
+
+// EncodePreviewToken serializes the token into an opaque, URL-safe string of the form payload.signature.
+func EncodePreviewToken(t PreviewToken) string {
+	payload := strconv.Itoa(t.ProjectID) + ":" + strconv.Itoa(t.Version) + ":" + strconv.FormatInt(t.ExpiresAt.Unix(), 10)
+	encoded := base64.RawURLEncoding.EncodeToString([]byte(payload))
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(signPreviewToken(encoded))
+}
+
+// DecodePreviewToken verifies the token's signature and parses it. It doesn't check expiry
+// or the version; callers compare those against the clock and the project.
+func DecodePreviewToken(token string) (PreviewToken, error) {
+	encoded, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return PreviewToken{}, ErrInvalidPreviewToken
+	}
+
+	gotSig, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(gotSig, signPreviewToken(encoded)) {
+		return PreviewToken{}, ErrInvalidPreviewToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return PreviewToken{}, ErrInvalidPreviewToken
+	}
+
+	parts := strings.Split(string(payload), ":")
+	if len(parts) != 3 {
+		return PreviewToken{}, ErrInvalidPreviewToken
+	}
+	projectID, err := strconv.Atoi(parts[0])
+	if err != nil || projectID <= 0 {
+		return PreviewToken{}, ErrInvalidPreviewToken
+	}
+	version, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return PreviewToken{}, ErrInvalidPreviewToken
+	}
+	expires, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return PreviewToken{}, ErrInvalidPreviewToken
+	}
+
+	return PreviewToken{ProjectID: projectID, Version: version, ExpiresAt: time.Unix(expires, 0).UTC()}, nil
+}
+
+func signPreviewToken(payload string) []byte {
+	mac := hmac.New(sha256.New, previewTokenKey)
+	mac.Write([]byte("preview:" + payload))
+	return mac.Sum(nil)
+}